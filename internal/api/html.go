@@ -28,6 +28,9 @@ const indexHTML = `<!DOCTYPE html>
         .tag-info { background: #e3f2fd; color: #1976d2; }
         .tag-warning { background: #fff3e0; color: #f57c00; }
         .tag-critical { background: #ffebee; color: #c62828; }
+        .tag-ok { background: #e8f5e9; color: #2e7d32; }
+        .tag-suspended, .tag-error, .tag-invalid { background: #fff3e0; color: #f57c00; }
+        .tag-delisted, .tag-unknown, .tag-malformed { background: #ffebee; color: #c62828; }
         .switch { position: relative; display: inline-block; width: 44px; height: 24px; }
         .switch input { opacity: 0; width: 0; height: 0; }
         .slider { position: absolute; cursor: pointer; top: 0; left: 0; right: 0; bottom: 0; background: #ccc; border-radius: 24px; transition: .3s; }
@@ -43,11 +46,12 @@ const indexHTML = `<!DOCTYPE html>
         <div class="card">
             <h2>股票管理</h2>
             <div class="form-row">
-                <input type="text" id="stockCode" placeholder="股票代码 如600519">
-                <input type="text" id="stockName" placeholder="股票名称">
+                <input type="text" id="stockCode" placeholder="代码/名称/拼音 如gzmt" list="securityList" oninput="searchSecurities(this.value)">
+                <datalist id="securityList"></datalist>
+                <input type="text" id="stockName" placeholder="股票名称（可自动填充）">
                 <button class="btn-primary" onclick="addStock()">添加股票</button>
             </div>
-            <table><thead><tr><th>代码</th><th>名称</th><th>操作</th></tr></thead><tbody id="stockList"></tbody></table>
+            <table><thead><tr><th>代码</th><th>名称</th><th>状态</th><th>操作</th></tr></thead><tbody id="stockList"></tbody></table>
         </div>
 
         <div class="card">
@@ -91,6 +95,10 @@ const indexHTML = `<!DOCTYPE html>
                 <label><input type="checkbox" id="dingtalkEnabled"> 钉钉</label>
                 <input type="text" id="dingtalkWebhook" placeholder="钉钉 Webhook URL" style="flex:1">
             </div>
+            <div class="form-row">
+                <label><input type="checkbox" id="marketEnabled"> 大盘告警</label>
+                <input type="text" id="marketWebhook" placeholder="市场告警专用飞书 Webhook URL" style="flex:1">
+            </div>
             <button class="btn-success" onclick="saveNotifiers()">保存通知配置</button>
         </div>
     </div>
@@ -109,20 +117,38 @@ const indexHTML = `<!DOCTYPE html>
             }).join('');
         }
 
+        const statusNames = {ok:'正常', suspended:'停牌', delisted:'退市', unknown:'无此代码', malformed:'数据异常', invalid:'行情异常', error:'获取失败'};
+
         async function loadStocks() {
             stocks = await api('/api/stocks');
-            document.getElementById('stockList').innerHTML = stocks.map(s =>
-                ` + "`" + `<tr><td>${s.code}</td><td>${s.name}</td><td><button class="btn-danger" onclick="delStock('${s.code}')">删除</button></td></tr>` + "`" + `
-            ).join('');
+            const status = await api('/api/stocks/status');
+            document.getElementById('stockList').innerHTML = stocks.map(s => {
+                const st = status[s.code];
+                const tag = st ? ` + "`" + `<span class="tag tag-${st}">${statusNames[st] || st}</span>` + "`" + ` : '-';
+                return ` + "`" + `<tr><td>${s.code}</td><td>${s.name}</td><td>${tag}</td><td><button class="btn-danger" onclick="delStock('${s.code}')">删除</button></td></tr>` + "`" + `;
+            }).join('');
             document.getElementById('ruleStock').innerHTML = '<option value="">全部股票</option>' +
                 stocks.map(s => ` + "`" + `<option value="${s.code}">${s.name}</option>` + "`" + `).join('');
         }
 
+        let searchTimer;
+        function searchSecurities(q) {
+            clearTimeout(searchTimer);
+            searchTimer = setTimeout(async () => {
+                if (!q) return;
+                const list = await api('/api/securities?q=' + encodeURIComponent(q));
+                document.getElementById('securityList').innerHTML = list.map(e =>
+                    ` + "`" + `<option value="${e.exchange}${e.code}">${e.name} ${e.pinyin}</option>` + "`" + `
+                ).join('');
+            }, 200);
+        }
+
         async function addStock() {
             const code = document.getElementById('stockCode').value;
             const name = document.getElementById('stockName').value;
-            if (!code || !name) return alert('请填写完整');
-            await api('/api/stocks', {method:'POST', body:JSON.stringify({code,name})});
+            if (!code) return alert('请填写股票代码');
+            const res = await api('/api/stocks', {method:'POST', body:JSON.stringify({code,name})});
+            if (res.error) return alert(res.error);
             document.getElementById('stockCode').value = '';
             document.getElementById('stockName').value = '';
             loadStocks();
@@ -184,13 +210,16 @@ const indexHTML = `<!DOCTYPE html>
             document.getElementById('serverchanKey').value = n.serverchan?.send_key || '';
             document.getElementById('dingtalkEnabled').checked = n.dingtalk?.enabled;
             document.getElementById('dingtalkWebhook').value = n.dingtalk?.webhook || '';
+            document.getElementById('marketEnabled').checked = n.market?.enabled;
+            document.getElementById('marketWebhook').value = n.market?.webhook || '';
         }
 
         async function saveNotifiers() {
             await api('/api/notifiers', {method:'PUT', body:JSON.stringify({
                 feishu: {enabled: document.getElementById('feishuEnabled').checked, webhook: document.getElementById('feishuWebhook').value},
                 serverchan: {enabled: document.getElementById('serverchanEnabled').checked, send_key: document.getElementById('serverchanKey').value},
-                dingtalk: {enabled: document.getElementById('dingtalkEnabled').checked, webhook: document.getElementById('dingtalkWebhook').value}
+                dingtalk: {enabled: document.getElementById('dingtalkEnabled').checked, webhook: document.getElementById('dingtalkWebhook').value},
+                market: {enabled: document.getElementById('marketEnabled').checked, webhook: document.getElementById('marketWebhook').value}
             })});
             alert('保存成功');
         }
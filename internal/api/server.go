@@ -1,23 +1,28 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
-	"regexp"
+	"time"
 
+	"stock-monitor/internal/datasource"
+	"stock-monitor/internal/model"
 	"stock-monitor/internal/rule"
+	"stock-monitor/internal/security"
 	"stock-monitor/internal/storage"
 
 	"github.com/google/uuid"
 )
 
-var stockCodeRegexp = regexp.MustCompile(`^\d{6}$`)
-
 // Server API服务器
 type Server struct {
-	store *storage.Store
-	mux   *http.ServeMux
+	store      *storage.Store
+	ds         datasource.DataSource
+	securities *security.List
+	mux        *http.ServeMux
 }
 
 // NewServer 创建API服务器
@@ -30,12 +35,24 @@ func NewServer(store *storage.Store) *Server {
 	return s
 }
 
+// SetDataSource 设置数据源，用于添加股票时校验代码及查询行情状态
+func (s *Server) SetDataSource(ds datasource.DataSource) {
+	s.ds = ds
+}
+
+// SetSecurityList 设置证券列表，用于代码检索和自动填充股票名称
+func (s *Server) SetSecurityList(list *security.List) {
+	s.securities = list
+}
+
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.mux.ServeHTTP(w, r)
 }
 
 func (s *Server) routes() {
 	s.mux.HandleFunc("/api/stocks", s.handleStocks)
+	s.mux.HandleFunc("/api/stocks/status", s.handleStockStatus)
+	s.mux.HandleFunc("/api/securities", s.handleSecurities)
 	s.mux.HandleFunc("/api/rules", s.handleRules)
 	s.mux.HandleFunc("/api/rule-types", s.handleRuleTypes)
 	s.mux.HandleFunc("/api/notifiers", s.handleNotifiers)
@@ -63,10 +80,28 @@ func (s *Server) handleStocks(w http.ResponseWriter, r *http.Request) {
 			s.errJSON(w, http.StatusBadRequest, "请求格式错误")
 			return
 		}
-		if !stockCodeRegexp.MatchString(stock.Code) {
-			s.errJSON(w, http.StatusBadRequest, "股票代码必须为6位数字")
+		sec, err := security.Parse(stock.Code)
+		if err != nil {
+			s.errJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		stock.Code = sec.Canonical()
+		status, quoteName, err := s.quoteStatus(r.Context(), stock.Code)
+		if err != nil {
+			s.errJSON(w, http.StatusBadGateway, "行情校验失败: "+err.Error())
 			return
 		}
+		// invalid 表示代码存在但行情异常，如开盘前或节假日无成交价，仍允许添加
+		if status != model.QuoteOK && status != model.QuoteSuspended && status != model.QuoteInvalid {
+			s.errJSON(w, http.StatusBadRequest, fmt.Sprintf("代码 %s 无有效行情: %s", stock.Code, status))
+			return
+		}
+		// 优先使用证券列表或行情中的标准名称
+		if entry, ok := s.lookupSecurity(stock.Code); ok {
+			stock.Name = entry.Name
+		} else if quoteName != "" {
+			stock.Name = quoteName
+		}
 		if stock.Name == "" {
 			s.errJSON(w, http.StatusBadRequest, "股票名称不能为空")
 			return
@@ -93,6 +128,71 @@ func (s *Server) handleStocks(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// quoteStatus 通过数据源查询单个代码的行情状态和名称，未设置数据源时视为正常
+func (s *Server) quoteStatus(ctx context.Context, code string) (model.QuoteStatus, string, error) {
+	if s.ds == nil {
+		return model.QuoteOK, "", nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	stocks, err := s.ds.GetRealTimeQuote(ctx, []string{code})
+	status := datasource.QuoteStatuses([]string{code}, stocks, err)[code]
+	if status == model.QuoteError {
+		return status, "", err
+	}
+	var name string
+	if len(stocks) > 0 {
+		name = stocks[0].Name
+	}
+	return status, name, nil
+}
+
+func (s *Server) lookupSecurity(code string) (security.Entry, bool) {
+	if s.securities == nil {
+		return security.Entry{}, false
+	}
+	return s.securities.Lookup(code)
+}
+
+// handleSecurities 按代码、名称或拼音首字母检索证券
+func (s *Server) handleSecurities(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	if s.securities == nil || q == "" {
+		s.json(w, []security.Entry{})
+		return
+	}
+	result := s.securities.Search(q, 20)
+	if result == nil {
+		result = []security.Entry{}
+	}
+	s.json(w, result)
+}
+
+// handleStockStatus 返回已添加股票的行情状态
+func (s *Server) handleStockStatus(w http.ResponseWriter, r *http.Request) {
+	stocks := s.store.GetStocks()
+	codes := make([]string, len(stocks))
+	for i, st := range stocks {
+		codes[i] = st.Code
+	}
+	if s.ds == nil || len(codes) == 0 {
+		s.json(w, map[string]model.QuoteStatus{})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	quotes, err := s.ds.GetRealTimeQuote(ctx, codes)
+	statuses := datasource.QuoteStatuses(codes, quotes, err)
+	for code, status := range statuses {
+		if status != model.QuoteOK {
+			slog.Warn("股票行情异常", "code", code, "status", status)
+		}
+	}
+	s.json(w, statuses)
+}
+
 func (s *Server) handleRules(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
@@ -103,7 +203,7 @@ func (s *Server) handleRules(w http.ResponseWriter, r *http.Request) {
 			s.errJSON(w, http.StatusBadRequest, "请求格式错误")
 			return
 		}
-		if err := s.validateRule(ri); err != nil {
+		if err := s.validateRule(&ri); err != nil {
 			s.errJSON(w, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -123,7 +223,7 @@ func (s *Server) handleRules(w http.ResponseWriter, r *http.Request) {
 			s.errJSON(w, http.StatusBadRequest, "缺少规则ID")
 			return
 		}
-		if err := s.validateRule(ri); err != nil {
+		if err := s.validateRule(&ri); err != nil {
 			s.errJSON(w, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -172,7 +272,8 @@ var validKLineTypes = map[string]bool{
 	"daily": true, "weekly": true, "monthly": true,
 }
 
-func (s *Server) validateRule(ri storage.RuleItem) error {
+// validateRule 校验规则配置，并将股票代码规范化
+func (s *Server) validateRule(ri *storage.RuleItem) error {
 	if ri.Name == "" {
 		return fmt.Errorf("规则名称不能为空")
 	}
@@ -182,8 +283,12 @@ func (s *Server) validateRule(ri storage.RuleItem) error {
 	if !validLevels[ri.Level] {
 		return fmt.Errorf("无效的告警级别: %s", ri.Level)
 	}
-	if ri.StockCode != "" && !stockCodeRegexp.MatchString(ri.StockCode) {
-		return fmt.Errorf("股票代码必须为6位数字")
+	if ri.StockCode != "" {
+		sec, err := security.Parse(ri.StockCode)
+		if err != nil {
+			return err
+		}
+		ri.StockCode = sec.Canonical()
 	}
 	if ri.KLineType != "" && !validKLineTypes[ri.KLineType] {
 		return fmt.Errorf("无效的K线类型: %s", ri.KLineType)
@@ -0,0 +1,197 @@
+package datasource
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"stock-monitor/internal/model"
+)
+
+// FactorSource 复权因子来源
+type FactorSource interface {
+	// GetFactors 获取复权因子，按日期升序
+	GetFactors(ctx context.Context, code string) ([]model.AdjustFactor, error)
+}
+
+// CSVFactorSource 从本地CSV加载复权因子
+//
+// 目录下每只股票一个文件，如 600519.csv，每行 "日期,因子"，
+// 日期支持 2006-01-02 与 20060102 两种格式，首行表头可选。文件变化时自动重新加载。
+type CSVFactorSource struct {
+	dir   string
+	mu    sync.Mutex
+	cache map[string]factorFile
+}
+
+// factorFile 已加载的因子文件
+type factorFile struct {
+	modTime time.Time
+	factors []model.AdjustFactor
+}
+
+// NewCSVFactorSource 创建CSV复权因子来源
+func NewCSVFactorSource(dir string) *CSVFactorSource {
+	return &CSVFactorSource{
+		dir:   dir,
+		cache: make(map[string]factorFile),
+	}
+}
+
+// GetFactors 获取复权因子，文件不存在时返回空列表
+func (c *CSVFactorSource) GetFactors(ctx context.Context, code string) ([]model.AdjustFactor, error) {
+	path := filepath.Join(c.dir, code+".csv")
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		c.mu.Lock()
+		delete(c.cache, code)
+		c.mu.Unlock()
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	cached, ok := c.cache[code]
+	c.mu.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) {
+		return cached.factors, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	factors, err := parseFactorCSV(f)
+	if err != nil {
+		return nil, fmt.Errorf("解析复权因子 %s 失败: %w", code, err)
+	}
+
+	c.mu.Lock()
+	c.cache[code] = factorFile{modTime: info.ModTime(), factors: factors}
+	c.mu.Unlock()
+	return factors, nil
+}
+
+func parseFactorCSV(r io.Reader) ([]model.AdjustFactor, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var factors []model.AdjustFactor
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 2 {
+			continue
+		}
+		date, err := parseFactorDate(record[0])
+		if err != nil {
+			if line == 1 {
+				continue // 表头
+			}
+			return nil, fmt.Errorf("第%d行日期无效: %s", line, record[0])
+		}
+		factor, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil || factor <= 0 {
+			return nil, fmt.Errorf("第%d行因子无效: %s", line, record[1])
+		}
+		factors = append(factors, model.AdjustFactor{Date: date, Factor: factor})
+	}
+
+	sort.Slice(factors, func(i, j int) bool {
+		return factors[i].Date.Before(factors[j].Date)
+	})
+	return factors, nil
+}
+
+func parseFactorDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("20060102", s, time.Local)
+}
+
+// ApplyAdjust 按复权因子对不复权K线做前/后复权
+//
+// 每根K线使用日期不晚于它的最近一个因子，早于首个因子的K线视为因子1。
+// 后复权价格 = 原价 × 因子；前复权价格 = 原价 × 因子 / 最新因子。
+// 成交量按相反比例换算，送转前后的成交股数可比。
+func ApplyAdjust(data *model.KLineData, factors []model.AdjustFactor, mode model.AdjustType) {
+	if data == nil || mode == "" || mode == model.AdjustNone || len(factors) == 0 {
+		return
+	}
+	if data.Adjust != "" && data.Adjust != model.AdjustNone {
+		return // 已复权
+	}
+
+	base := 1.0
+	if mode == model.AdjustQFQ {
+		base = factors[len(factors)-1].Factor
+	}
+
+	idx := 0
+	factor := 1.0
+	for i := range data.Lines {
+		line := &data.Lines[i]
+		for idx < len(factors) && !factors[idx].Date.After(line.Time) {
+			factor = factors[idx].Factor
+			idx++
+		}
+		ratio := factor / base
+		line.Open *= ratio
+		line.High *= ratio
+		line.Low *= ratio
+		line.Close *= ratio
+		line.Volume = int64(math.Round(float64(line.Volume) / ratio))
+	}
+	data.Adjust = mode
+}
+
+// AdjustedDataSource 复权数据源，对内部数据源返回的K线统一复权
+type AdjustedDataSource struct {
+	DataSource
+	factors FactorSource
+	mode    model.AdjustType
+}
+
+// NewAdjustedDataSource 创建复权数据源
+func NewAdjustedDataSource(ds DataSource, factors FactorSource, mode model.AdjustType) *AdjustedDataSource {
+	return &AdjustedDataSource{
+		DataSource: ds,
+		factors:    factors,
+		mode:       mode,
+	}
+}
+
+// GetKLine 获取复权后的K线数据
+func (a *AdjustedDataSource) GetKLine(ctx context.Context, code string, ktype model.KLineType, count int) (*model.KLineData, error) {
+	data, err := a.DataSource.GetKLine(ctx, code, ktype, count)
+	if err != nil {
+		return nil, err
+	}
+	factors, err := a.factors.GetFactors(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	ApplyAdjust(data, factors, a.mode)
+	return data, nil
+}
@@ -0,0 +1,131 @@
+package datasource
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stock-monitor/internal/model"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+func TestParseFactorCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    []model.AdjustFactor
+		wantErr bool
+	}{
+		{"带表头", "date,factor\n2024-06-19,8.6079\n2023-06-30,8.3612\n", []model.AdjustFactor{
+			{Date: day(2023, 6, 30), Factor: 8.3612}, {Date: day(2024, 6, 19), Factor: 8.6079},
+		}, false},
+		{"紧凑日期无表头", "20230630, 8.3612\n", []model.AdjustFactor{{Date: day(2023, 6, 30), Factor: 8.3612}}, false},
+		{"日期无效", "date,factor\n2023-06-30,8.3612\nbad,1\n", nil, true},
+		{"因子非正", "2023-06-30,0\n", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseFactorCSV(strings.NewReader(tt.csv))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if !got[i].Date.Equal(tt.want[i].Date) || got[i].Factor != tt.want[i].Factor {
+				t.Errorf("%s: factor %d = %+v, want %+v", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestApplyAdjust(t *testing.T) {
+	// 6月20日 10送10，因子由1变为2
+	factors := []model.AdjustFactor{{Date: day(2024, 6, 20), Factor: 2}}
+	raw := func() *model.KLineData {
+		return &model.KLineData{Code: "600000", Type: model.KLineDaily, Lines: []model.KLine{
+			{Time: day(2024, 6, 19), Open: 20, High: 21, Low: 19, Close: 20, Volume: 1000},
+			{Time: day(2024, 6, 20), Open: 10, High: 10.5, Low: 9.5, Close: 10, Volume: 2000},
+		}}
+	}
+
+	tests := []struct {
+		mode   model.AdjustType
+		closes []float64
+		vols   []int64
+	}{
+		{model.AdjustQFQ, []float64{10, 10}, []int64{2000, 2000}},
+		{model.AdjustHFQ, []float64{20, 20}, []int64{1000, 1000}},
+		{model.AdjustNone, []float64{20, 10}, []int64{1000, 2000}},
+	}
+	for _, tt := range tests {
+		data := raw()
+		ApplyAdjust(data, factors, tt.mode)
+		for i, line := range data.Lines {
+			if math.Abs(line.Close-tt.closes[i]) > 1e-9 || line.Volume != tt.vols[i] {
+				t.Errorf("%s bar %d: close=%v vol=%d, want %v/%d", tt.mode, i, line.Close, line.Volume, tt.closes[i], tt.vols[i])
+			}
+		}
+	}
+
+	// 已复权的K线不重复处理
+	data := raw()
+	data.Adjust = model.AdjustQFQ
+	ApplyAdjust(data, factors, model.AdjustQFQ)
+	if data.Lines[0].Close != 20 {
+		t.Errorf("adjusted twice: close=%v", data.Lines[0].Close)
+	}
+}
+
+func TestCSVFactorSourceReload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "600519.csv")
+	src := NewCSVFactorSource(dir)
+	ctx := context.Background()
+
+	if factors, err := src.GetFactors(ctx, "600519"); err != nil || factors != nil {
+		t.Fatalf("missing file: %v, %v", factors, err)
+	}
+
+	write := func(content string, mtime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("2023-06-30,8.3612\n", time.Now().Add(-time.Hour))
+	factors, err := src.GetFactors(ctx, "600519")
+	if err != nil || len(factors) != 1 {
+		t.Fatalf("first load: %v, %v", factors, err)
+	}
+
+	// 新增除权除息记录后无需重启即可生效
+	write("2023-06-30,8.3612\n2024-06-19,8.6079\n", time.Now())
+	factors, err = src.GetFactors(ctx, "600519")
+	if err != nil || len(factors) != 2 || factors[1].Factor != 8.6079 {
+		t.Fatalf("after update: %v, %v", factors, err)
+	}
+
+	os.Remove(path)
+	if factors, _ := src.GetFactors(ctx, "600519"); factors != nil {
+		t.Errorf("after remove: %v", factors)
+	}
+}
+
+func TestNewRejectsHFQ(t *testing.T) {
+	if _, err := New(Config{Name: "sina", Adjust: model.AdjustHFQ}); err == nil {
+		t.Error("New with hfq should fail")
+	}
+}
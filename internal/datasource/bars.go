@@ -0,0 +1,250 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+)
+
+// minuteBar 1分钟K线，minute 为其结束时刻对应的已交易分钟数
+type minuteBar struct {
+	minute int
+	bar    model.KLine
+}
+
+// barSeries 单只证券当日的合成K线
+type barSeries struct {
+	market    *security.Market
+	day       time.Time // 交易日零点（市场当地时间）
+	lastTime  time.Time
+	lastVol   int64
+	lastQuote model.Stock
+	bars      []minuteBar
+}
+
+// BarAggregator 由实时行情快照合成分钟K线
+//
+// 成交量取相邻两次快照累计成交量之差；当日首个快照只作为成交量基准，
+// 因此中途启动时第一根K线的成交量偏小。K线时间为结束时间，与数据源一致，
+// 交易时段按证券所属市场划分。
+type BarAggregator struct {
+	mu     sync.Mutex
+	series map[string]*barSeries
+}
+
+// NewBarAggregator 创建K线合成器
+func NewBarAggregator() *BarAggregator {
+	return &BarAggregator{series: make(map[string]*barSeries)}
+}
+
+// Update 写入一次行情快照，停牌、无价格或时间倒退的快照被忽略
+func (a *BarAggregator) Update(stock *model.Stock) {
+	if stock == nil || stock.Price <= 0 || stock.Time.IsZero() ||
+		(stock.Status != "" && stock.Status != model.QuoteOK) {
+		return
+	}
+	t := stock.Time
+	market := security.MarketOf(stock.Code)
+	day := market.Date(t)
+
+	key := seriesKey(stock.Code)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s, ok := a.series[key]
+	if !ok || !s.day.Equal(day) {
+		s = &barSeries{market: market, day: day, lastVol: stock.Volume}
+		if t.Before(market.OpenTime(day)) {
+			s.lastVol = 0 // 集合竞价成交量计入第一根K线
+		}
+		a.series[key] = s
+	}
+	if !t.After(s.lastTime) && !s.lastTime.IsZero() {
+		return
+	}
+
+	delta := stock.Volume - s.lastVol
+	if delta < 0 {
+		delta = 0
+	}
+	s.lastVol = stock.Volume
+	s.lastTime = t
+	s.lastQuote = *stock
+
+	minute := s.market.TradingMinute(t)
+	if n := len(s.bars); n > 0 && s.bars[n-1].minute == minute {
+		bar := &s.bars[n-1].bar
+		bar.High = max(bar.High, stock.Price)
+		bar.Low = min(bar.Low, stock.Price)
+		bar.Close = stock.Price
+		bar.Volume += delta
+		return
+	}
+	s.bars = append(s.bars, minuteBar{
+		minute: minute,
+		bar: model.KLine{
+			Time:   s.market.BarEnd(day, minute),
+			Open:   stock.Price,
+			High:   stock.Price,
+			Low:    stock.Price,
+			Close:  stock.Price,
+			Volume: delta,
+		},
+	})
+}
+
+// seriesKey 合成K线按规范代码保存，sh600519、600519.SH 与 600519 对应同一序列
+func seriesKey(code string) string {
+	if sec, err := security.Parse(code); err == nil {
+		return sec.Canonical()
+	}
+	return code
+}
+
+// Bars 返回当日合成的K线，支持分钟K线与日K
+func (a *BarAggregator) Bars(code string, ktype model.KLineType) []model.KLine {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s, ok := a.series[seriesKey(code)]
+	if !ok || len(s.bars) == 0 {
+		return nil
+	}
+
+	if ktype == model.KLineDaily {
+		q := s.lastQuote
+		return []model.KLine{{
+			Time:   s.day,
+			Open:   q.Open,
+			High:   q.High,
+			Low:    q.Low,
+			Close:  q.Price,
+			Volume: q.Volume,
+		}}
+	}
+
+	interval := ktype.Minutes()
+	if interval == 0 {
+		return nil
+	}
+	var result []model.KLine
+	lastEnd := -1
+	total := s.market.Minutes()
+	for _, mb := range s.bars {
+		end := min((mb.minute+interval-1)/interval*interval, total)
+		if end != lastEnd {
+			bar := mb.bar
+			bar.Time = s.market.BarEnd(s.day, end)
+			result = append(result, bar)
+			lastEnd = end
+			continue
+		}
+		bar := &result[len(result)-1]
+		bar.High = max(bar.High, mb.bar.High)
+		bar.Low = min(bar.Low, mb.bar.Low)
+		bar.Close = mb.bar.Close
+		bar.Volume += mb.bar.Volume
+	}
+	return result
+}
+
+// Merge 将合成K线追加到历史K线之后，只追加晚于最后一根历史K线的部分
+//
+// 合成K线为不复权价格，与前复权的最新价一致；后复权K线不做合并。
+func (a *BarAggregator) Merge(data *model.KLineData) *model.KLineData {
+	if data == nil || data.Adjust == model.AdjustHFQ {
+		return data
+	}
+	bars := a.Bars(data.Code, data.Type)
+	if len(bars) == 0 {
+		return data
+	}
+
+	var last time.Time
+	if n := len(data.Lines); n > 0 {
+		last = data.Lines[n-1].Time
+	}
+	appended := false
+	for _, bar := range bars {
+		if bar.Time.After(last) {
+			data.Lines = append(data.Lines, bar)
+			appended = true
+		}
+	}
+	if appended {
+		markForming(data, a.lastTime(data.Code))
+	}
+	return data
+}
+
+// lastTime 最近一次行情快照时间
+func (a *BarAggregator) lastTime(code string) time.Time {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if s, ok := a.series[seriesKey(code)]; ok {
+		return s.lastTime
+	}
+	return time.Time{}
+}
+
+// RealtimeBarDataSource 实时K线数据源：用行情快照合成的K线补齐历史K线
+//
+// 获取行情时同步写入合成器；1分钟K线远程接口不提供，仅返回当日合成数据。
+type RealtimeBarDataSource struct {
+	DataSource
+	agg *BarAggregator
+}
+
+// NewRealtimeBarDataSource 创建实时K线数据源
+func NewRealtimeBarDataSource(ds DataSource, agg *BarAggregator) *RealtimeBarDataSource {
+	if agg == nil {
+		agg = NewBarAggregator()
+	}
+	return &RealtimeBarDataSource{DataSource: ds, agg: agg}
+}
+
+// Aggregator 返回内部的K线合成器
+func (r *RealtimeBarDataSource) Aggregator() *BarAggregator {
+	return r.agg
+}
+
+// GetRealTimeQuote 获取实时行情并写入合成器，部分失败时已返回的行情同样写入
+func (r *RealtimeBarDataSource) GetRealTimeQuote(ctx context.Context, codes []string) ([]*model.Stock, error) {
+	stocks, err := r.DataSource.GetRealTimeQuote(ctx, codes)
+	for _, stock := range stocks {
+		r.agg.Update(stock)
+	}
+	return stocks, err
+}
+
+// GetKLine 获取历史K线并追加当日合成K线
+func (r *RealtimeBarDataSource) GetKLine(ctx context.Context, code string, ktype model.KLineType, count int) (*model.KLineData, error) {
+	if ktype == model.KLine1Min {
+		bars := r.agg.Bars(code, ktype)
+		if len(bars) == 0 {
+			return nil, fmt.Errorf("no realtime bars for %s", code)
+		}
+		if count > 0 && len(bars) > count {
+			bars = bars[len(bars)-count:]
+		}
+		data := &model.KLineData{Code: code, Type: ktype, Adjust: model.AdjustNone, Lines: bars}
+		markForming(data, r.agg.lastTime(code))
+		return data, nil
+	}
+
+	data, err := r.DataSource.GetKLine(ctx, code, ktype, count)
+	if err != nil {
+		return nil, err
+	}
+	return r.agg.Merge(data), nil
+}
+
+// ListSecurities 透传内部数据源的证券列表
+func (r *RealtimeBarDataSource) ListSecurities(ctx context.Context) ([]security.Entry, error) {
+	return listSecurities(ctx, r.DataSource)
+}
@@ -0,0 +1,131 @@
+package datasource
+
+import (
+	"testing"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+)
+
+func TestBarAggregatorBoundaries(t *testing.T) {
+	loc := security.MarketCN.Location
+	at := func(h, m, s int) time.Time { return time.Date(2024, 1, 3, h, m, s, 0, loc) }
+
+	agg := NewBarAggregator()
+	snapshots := []struct {
+		time   time.Time
+		price  float64
+		volume int64
+	}{
+		{at(9, 25, 3), 10.00, 1000},   // 集合竞价计入第一根
+		{at(9, 30, 0), 10.10, 1500},   // 开盘时刻属于 09:31
+		{at(9, 31, 0), 10.05, 1800},   // 整分属于以该分钟结束的K线
+		{at(9, 31, 1), 10.20, 2500},   // 进入 09:32
+		{at(9, 31, 1), 99.00, 9999},   // 时间未前进，忽略
+		{at(11, 29, 30), 10.30, 3000}, // 11:30 最后一根
+		{at(11, 45, 0), 10.40, 3100},  // 午休计入 11:30
+		{at(13, 0, 30), 10.35, 3600},  // 下午第一根 13:01
+	}
+	for _, s := range snapshots {
+		agg.Update(&model.Stock{Code: "600519", Price: s.price, Open: 10, High: 10.4, Low: 10, Volume: s.volume, Time: s.time})
+	}
+
+	tests := []struct {
+		ktype model.KLineType
+		want  []model.KLine
+	}{
+		{model.KLine1Min, []model.KLine{
+			{Time: at(9, 31, 0), Open: 10.00, High: 10.10, Low: 10.00, Close: 10.05, Volume: 1800},
+			{Time: at(9, 32, 0), Open: 10.20, High: 10.20, Low: 10.20, Close: 10.20, Volume: 700},
+			{Time: at(11, 30, 0), Open: 10.30, High: 10.40, Low: 10.30, Close: 10.40, Volume: 600},
+			{Time: at(13, 1, 0), Open: 10.35, High: 10.35, Low: 10.35, Close: 10.35, Volume: 500},
+		}},
+		{model.KLine5Min, []model.KLine{
+			{Time: at(9, 35, 0), Open: 10.00, High: 10.20, Low: 10.00, Close: 10.20, Volume: 2500},
+			{Time: at(11, 30, 0), Open: 10.30, High: 10.40, Low: 10.30, Close: 10.40, Volume: 600},
+			{Time: at(13, 5, 0), Open: 10.35, High: 10.35, Low: 10.35, Close: 10.35, Volume: 500},
+		}},
+		{model.KLineDaily, []model.KLine{
+			{Time: at(0, 0, 0), Open: 10, High: 10.4, Low: 10, Close: 10.35, Volume: 3600},
+		}},
+	}
+	for _, tt := range tests {
+		got := agg.Bars("600519", tt.ktype)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %d bars %+v, want %d", tt.ktype, len(got), got, len(tt.want))
+			continue
+		}
+		for i, w := range tt.want {
+			if !got[i].Time.Equal(w.Time) || got[i].Open != w.Open || got[i].High != w.High ||
+				got[i].Low != w.Low || got[i].Close != w.Close || got[i].Volume != w.Volume {
+				t.Errorf("%s bar %d = %+v, want %+v", tt.ktype, i, got[i], w)
+			}
+		}
+	}
+}
+
+func TestBarAggregatorIgnoresInvalid(t *testing.T) {
+	loc := security.MarketCN.Location
+	now := time.Date(2024, 1, 3, 10, 0, 0, 0, loc)
+	agg := NewBarAggregator()
+	agg.Update(&model.Stock{Code: "600519", Price: 0, Volume: 100, Time: now})
+	agg.Update(&model.Stock{Code: "600519", Price: 10, Volume: 100, Time: now, Status: model.QuoteSuspended})
+	agg.Update(&model.Stock{Code: "600519", Price: 10, Volume: 100})
+	if bars := agg.Bars("600519", model.KLine1Min); bars != nil {
+		t.Errorf("got bars %+v", bars)
+	}
+}
+
+func TestBarAggregatorMerge(t *testing.T) {
+	loc := security.MarketCN.Location
+	at := func(h, m int) time.Time { return time.Date(2024, 1, 3, h, m, 0, 0, loc) }
+	agg := NewBarAggregator()
+	agg.Update(&model.Stock{Code: "600519", Price: 10, Volume: 0, Time: at(9, 30)})
+	agg.Update(&model.Stock{Code: "600519", Price: 11, Volume: 100, Time: at(9, 36).Add(time.Second)})
+
+	tests := []struct {
+		name    string
+		adjust  model.AdjustType
+		history []model.KLine
+		want    int
+	}{
+		{"追加晚于历史的部分", model.AdjustNone, []model.KLine{{Time: at(9, 35)}}, 2},
+		{"历史已覆盖", model.AdjustQFQ, []model.KLine{{Time: at(9, 35)}, {Time: at(9, 40)}}, 2},
+		{"后复权不合并", model.AdjustHFQ, []model.KLine{{Time: at(9, 35)}}, 1},
+	}
+	for _, tt := range tests {
+		data := &model.KLineData{Code: "600519", Type: model.KLine5Min, Adjust: tt.adjust, Lines: append([]model.KLine(nil), tt.history...)}
+		got := agg.Merge(data)
+		if len(got.Lines) != tt.want {
+			t.Errorf("%s: got %d lines, want %d", tt.name, len(got.Lines), tt.want)
+		}
+	}
+
+	data := agg.Merge(&model.KLineData{Code: "600519", Type: model.KLine5Min, Lines: []model.KLine{{Time: at(9, 35)}}})
+	if !data.Forming || !data.Lines[1].Time.Equal(at(9, 40)) {
+		t.Errorf("merged forming=%v last=%v", data.Forming, data.Lines[1].Time)
+	}
+}
+
+func TestBarAggregatorMergePrefixedCode(t *testing.T) {
+	loc := security.MarketCN.Location
+	at := func(h, m int) time.Time { return time.Date(2024, 1, 3, h, m, 0, 0, loc) }
+	agg := NewBarAggregator()
+	agg.Update(&model.Stock{Code: "600519", Price: 10, Volume: 0, Time: at(9, 30)})
+	agg.Update(&model.Stock{Code: "600519", Price: 11, Volume: 100, Time: at(9, 36).Add(time.Second)})
+
+	// 请求时的代码写法与行情中的规范代码不同
+	for _, code := range []string{"sh600519", "600519.SH", "SH600519"} {
+		data := agg.Merge(&model.KLineData{Code: code, Type: model.KLine5Min, Lines: []model.KLine{{Time: at(9, 35)}}})
+		if len(data.Lines) != 2 || !data.Forming {
+			t.Errorf("%s: got %d lines forming=%v, want 2 forming", code, len(data.Lines), data.Forming)
+		}
+	}
+
+	// 行情为带前缀写法时同样按规范代码保存
+	agg.Update(&model.Stock{Code: "sz000001", Price: 9, Volume: 0, Time: at(9, 30)})
+	if bars := agg.Bars("000001", model.KLine1Min); len(bars) != 1 {
+		t.Errorf("000001: got %d bars, want 1", len(bars))
+	}
+}
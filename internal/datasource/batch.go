@@ -0,0 +1,227 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+)
+
+const (
+	defaultChunkSize    = 80 // 单次行情请求的代码数，避免URL过长
+	defaultQuoteWorkers = 4  // 并发请求数
+	defaultRatePerSec   = 10 // 每秒请求数上限
+)
+
+// QuoteErrors 按代码记录的行情获取错误
+//
+// GetRealTimeQuote 部分失败时返回成功的行情，同时以 QuoteErrors 作为 error 返回，
+// 调用方可通过 errors.As 取出失败的代码。
+type QuoteErrors map[string]error
+
+func (e QuoteErrors) Error() string {
+	codes := e.Codes()
+	if len(codes) > 5 {
+		return fmt.Sprintf("%d只股票行情获取失败: %s 等", len(codes), strings.Join(codes[:5], ","))
+	}
+	return fmt.Sprintf("%d只股票行情获取失败: %s", len(codes), strings.Join(codes, ","))
+}
+
+// Codes 失败的代码，已排序
+func (e QuoteErrors) Codes() []string {
+	codes := make([]string, 0, len(e))
+	for code := range e {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// CodeError 单个代码无有效行情的原因
+type CodeError struct {
+	Code   string
+	Status model.QuoteStatus
+	Reason string
+}
+
+func (e *CodeError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("%s: %s (%s)", e.Code, e.Status, e.Reason)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Status)
+}
+
+// QuoteStatuses 汇总 GetRealTimeQuote 的结果，返回每个请求代码的行情状态
+func QuoteStatuses(codes []string, stocks []*model.Stock, err error) map[string]model.QuoteStatus {
+	got := make(map[string]model.QuoteStatus, len(stocks))
+	for _, st := range stocks {
+		status := st.Status
+		if status == "" {
+			status = model.QuoteOK
+		}
+		got[st.Code] = status
+	}
+
+	var failed QuoteErrors
+	errors.As(err, &failed)
+
+	result := make(map[string]model.QuoteStatus, len(codes))
+	for _, code := range codes {
+		if sec, perr := security.Parse(code); perr == nil {
+			if status, ok := got[sec.Canonical()]; ok {
+				result[code] = status
+				continue
+			}
+		}
+		var codeErr *CodeError
+		switch {
+		case errors.As(failed[code], &codeErr):
+			result[code] = codeErr.Status
+		case failed[code] != nil, err != nil && failed == nil:
+			result[code] = model.QuoteError
+		default:
+			result[code] = model.QuoteUnknown
+		}
+	}
+	return result
+}
+
+// missingCodes 将请求了但响应中没有的代码记为未知代码
+func missingCodes(codes []string, stocks []*model.Stock, failed QuoteErrors) QuoteErrors {
+	got := make(map[string]bool, len(stocks))
+	for _, st := range stocks {
+		got[st.Code] = true
+	}
+	for _, code := range codes {
+		if _, ok := failed[code]; ok {
+			continue
+		}
+		sec, err := security.Parse(code)
+		if err != nil {
+			failed[code] = &CodeError{Code: code, Status: model.QuoteMalformed, Reason: err.Error()}
+			continue
+		}
+		if !got[sec.Canonical()] {
+			failed[code] = &CodeError{Code: code, Status: model.QuoteUnknown}
+		}
+	}
+	return failed
+}
+
+// rateLimiter 简单的匀速限流器，同一数据源的所有请求共享
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(perSecond float64) *rateLimiter {
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// Wait 等待下一个可用的请求时间点
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// quoteFetcher 获取一批代码的行情
+type quoteFetcher func(ctx context.Context, codes []string) ([]*model.Stock, error)
+
+// fetchChunked 将代码分批并发获取行情
+//
+// 无效代码、请求失败批次中的代码以及各批次返回的 QuoteErrors 合并记入 QuoteErrors；
+// 全部请求均失败时直接返回请求错误。
+func fetchChunked(ctx context.Context, codes []string, chunkSize, workers int, fetch quoteFetcher) ([]*model.Stock, error) {
+	failed := QuoteErrors{}
+	valid := make([]string, 0, len(codes))
+	for _, code := range codes {
+		if _, err := security.Parse(code); err != nil {
+			failed[code] = &CodeError{Code: code, Status: model.QuoteMalformed, Reason: err.Error()}
+			continue
+		}
+		valid = append(valid, code)
+	}
+
+	var chunks [][]string
+	for start := 0; start < len(valid); start += chunkSize {
+		end := start + chunkSize
+		if end > len(valid) {
+			end = len(valid)
+		}
+		chunks = append(chunks, valid[start:end])
+	}
+
+	results := make([][]*model.Stock, len(chunks))
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, chunk []string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i], errs[i] = fetch(ctx, chunk)
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	var stocks []*model.Stock
+	var firstErr error
+	for i, err := range errs {
+		var partial QuoteErrors
+		if errors.As(err, &partial) {
+			for code, codeErr := range partial {
+				failed[code] = codeErr
+			}
+			stocks = append(stocks, results[i]...)
+			continue
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			for _, code := range chunks[i] {
+				failed[code] = err
+			}
+			continue
+		}
+		stocks = append(stocks, results[i]...)
+	}
+
+	switch {
+	case len(failed) == 0:
+		return stocks, nil
+	case len(stocks) == 0 && firstErr != nil:
+		return nil, firstErr
+	}
+	return stocks, failed
+}
@@ -0,0 +1,154 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"stock-monitor/internal/model"
+)
+
+func TestFetchChunked(t *testing.T) {
+	codes := []string{"600519", "000001", "bad", "300750", "601398", "000002", "688981", "510300"}
+
+	tests := []struct {
+		name      string
+		chunkSize int
+		workers   int
+		fail      func(chunk []string) error // 返回非空时整批失败
+		missing   map[string]bool            // 批次内部分失败的代码
+		wantOK    int
+		wantErrs  map[string]model.QuoteStatus
+		wantBatch int
+	}{
+		{
+			name: "全部成功", chunkSize: 3, workers: 2,
+			wantOK: 7, wantErrs: map[string]model.QuoteStatus{"bad": model.QuoteMalformed}, wantBatch: 3,
+		},
+		{
+			name: "批次内部分失败", chunkSize: 2, workers: 4, missing: map[string]bool{"000002": true},
+			wantOK: 6, wantErrs: map[string]model.QuoteStatus{"bad": model.QuoteMalformed, "000002": model.QuoteUnknown}, wantBatch: 4,
+		},
+		{
+			name: "整批请求失败", chunkSize: 4, workers: 1,
+			fail: func(chunk []string) error {
+				if chunk[0] == "600519" {
+					return errors.New("timeout")
+				}
+				return nil
+			},
+			wantOK: 3,
+			wantErrs: map[string]model.QuoteStatus{
+				"bad": model.QuoteMalformed, "600519": model.QuoteError, "000001": model.QuoteError,
+				"300750": model.QuoteError, "601398": model.QuoteError,
+			},
+			wantBatch: 2,
+		},
+	}
+	for _, tt := range tests {
+		var mu sync.Mutex
+		var batches, running, maxRunning int
+		fetch := func(ctx context.Context, chunk []string) ([]*model.Stock, error) {
+			mu.Lock()
+			batches++
+			running++
+			maxRunning = max(maxRunning, running)
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			defer func() {
+				mu.Lock()
+				running--
+				mu.Unlock()
+			}()
+
+			if len(chunk) > tt.chunkSize {
+				t.Errorf("%s: chunk of %d codes", tt.name, len(chunk))
+			}
+			if tt.fail != nil {
+				if err := tt.fail(chunk); err != nil {
+					return nil, err
+				}
+			}
+			var stocks []*model.Stock
+			failed := QuoteErrors{}
+			for _, code := range chunk {
+				if tt.missing[code] {
+					failed[code] = &CodeError{Code: code, Status: model.QuoteUnknown}
+					continue
+				}
+				stocks = append(stocks, &model.Stock{Code: code, Price: 1})
+			}
+			if len(failed) > 0 {
+				return stocks, failed
+			}
+			return stocks, nil
+		}
+
+		stocks, err := fetchChunked(context.Background(), codes, tt.chunkSize, tt.workers, fetch)
+		if len(stocks) != tt.wantOK {
+			t.Errorf("%s: got %d stocks, want %d", tt.name, len(stocks), tt.wantOK)
+		}
+		if batches != tt.wantBatch {
+			t.Errorf("%s: %d batches, want %d", tt.name, batches, tt.wantBatch)
+		}
+		if maxRunning > tt.workers {
+			t.Errorf("%s: %d concurrent batches, limit %d", tt.name, maxRunning, tt.workers)
+		}
+
+		statuses := QuoteStatuses(codes, stocks, err)
+		var bad []string
+		for code, status := range statuses {
+			want, ok := tt.wantErrs[code]
+			if !ok {
+				want = model.QuoteOK
+			}
+			if status != want {
+				bad = append(bad, code+"="+string(status))
+			}
+		}
+		sort.Strings(bad)
+		if len(bad) > 0 {
+			t.Errorf("%s: unexpected statuses %s", tt.name, strings.Join(bad, ","))
+		}
+	}
+}
+
+func TestFetchChunkedAllFailed(t *testing.T) {
+	want := errors.New("network down")
+	fetch := func(ctx context.Context, chunk []string) ([]*model.Stock, error) { return nil, want }
+	stocks, err := fetchChunked(context.Background(), []string{"600519", "000001"}, 1, 2, fetch)
+	if stocks != nil || !errors.Is(err, want) {
+		t.Fatalf("stocks = %v, err = %v", stocks, err)
+	}
+}
+
+// failingTransport 每次请求都返回网络错误
+type failingTransport struct{ calls int }
+
+func (f *failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	f.calls++
+	return nil, errors.New("connection reset")
+}
+
+func TestDoWithRetryRateLimited(t *testing.T) {
+	transport := &failingTransport{}
+	limiter := newRateLimiter(100)
+	req, _ := http.NewRequest(http.MethodGet, "http://example.invalid/", nil)
+
+	start := time.Now()
+	if _, err := doWithRetry(&http.Client{Transport: transport}, req, 2, limiter); err == nil {
+		t.Fatal("expected error")
+	}
+	if transport.calls != 2 {
+		t.Fatalf("calls = %d, want 2", transport.calls)
+	}
+	// 重试前同样占用限流配额：最后一次取号发生在退避之后
+	if !limiter.next.After(start.Add(time.Second)) {
+		t.Errorf("limiter not consulted on retry: next = %v", limiter.next.Sub(start))
+	}
+}
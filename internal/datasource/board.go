@@ -0,0 +1,260 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+)
+
+// BoardSource 可提供行业/概念板块数据的来源
+type BoardSource interface {
+	// ListBoards 获取指定类型的板块列表及板块指数行情
+	ListBoards(ctx context.Context, typ model.BoardType) ([]*model.Board, error)
+	// GetBoardMembers 获取板块成分股代码
+	GetBoardMembers(ctx context.Context, boardCode string) ([]string, error)
+}
+
+const (
+	// 板块范围: 行业板块 m:90+t:2，概念板块 m:90+t:3
+	eastmoneyIndustryFilter = "m:90+t:2"
+	eastmoneyConceptFilter  = "m:90+t:3"
+	// 板块字段: 代码,名称,最新点位,涨跌幅,上涨家数,下跌家数
+	eastmoneyBoardFields = "f12,f14,f2,f3,f104,f105"
+)
+
+// ListBoards 分页获取东方财富行业/概念板块
+func (e *EastmoneyDataSource) ListBoards(ctx context.Context, typ model.BoardType) ([]*model.Board, error) {
+	var filter string
+	switch typ {
+	case model.BoardIndustry:
+		filter = eastmoneyIndustryFilter
+	case model.BoardConcept:
+		filter = eastmoneyConceptFilter
+	default:
+		return nil, fmt.Errorf("unknown board type: %s", typ)
+	}
+
+	var boards []*model.Board
+	err := e.listPages(ctx, filter, eastmoneyBoardFields, func(item map[string]interface{}) {
+		code, _ := item["f12"].(string)
+		name, _ := item["f14"].(string)
+		if code == "" || name == "" {
+			return
+		}
+		boards = append(boards, &model.Board{
+			Code:          code,
+			Name:          name,
+			Type:          typ,
+			Price:         parseFloat(item["f2"]),
+			ChangePercent: parseFloat(item["f3"]),
+			Up:            int(parseFloat(item["f104"])),
+			Down:          int(parseFloat(item["f105"])),
+		})
+	})
+	return boards, err
+}
+
+// GetBoardMembers 获取东方财富板块成分股
+func (e *EastmoneyDataSource) GetBoardMembers(ctx context.Context, boardCode string) ([]string, error) {
+	var codes []string
+	err := e.listPages(ctx, "b:"+boardCode, "f12,f13,f14", func(item map[string]interface{}) {
+		code, _ := item["f12"].(string)
+		if sec, err := symbolFromMarket(int(parseFloat(item["f13"])), code); err == nil {
+			codes = append(codes, sec.Canonical())
+		}
+	})
+	return codes, err
+}
+
+// FixtureBoardSource 从本地JSON加载板块数据，用于离线运行和测试
+//
+// 文件格式为 {"boards":[{"code":"BK1036","name":"半导体","type":"industry","change_percent":3.2,"members":["603986"]}]}，
+// 文件变化时自动重新加载。
+type FixtureBoardSource struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	boards  []*model.Board
+}
+
+// NewFixtureBoardSource 创建本地板块数据来源
+func NewFixtureBoardSource(path string) *FixtureBoardSource {
+	return &FixtureBoardSource{path: path}
+}
+
+func (f *FixtureBoardSource) load() ([]*model.Board, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return nil, err
+	}
+	if f.boards != nil && info.ModTime().Equal(f.modTime) {
+		return f.boards, nil
+	}
+
+	raw, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Boards []*model.Board `json:"boards"`
+	}
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("解析板块文件 %s 失败: %w", f.path, err)
+	}
+	for _, b := range file.Boards {
+		for i, code := range b.Members {
+			if sec, err := security.Parse(code); err == nil {
+				b.Members[i] = sec.Canonical()
+			}
+		}
+	}
+	f.boards, f.modTime = file.Boards, info.ModTime()
+	return f.boards, nil
+}
+
+// ListBoards 返回指定类型的板块，成分股一并返回；返回的板块为缓存的副本
+func (f *FixtureBoardSource) ListBoards(ctx context.Context, typ model.BoardType) ([]*model.Board, error) {
+	boards, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+	var result []*model.Board
+	for _, b := range boards {
+		if b.Type == typ {
+			board := *b
+			board.Members = append([]string(nil), b.Members...)
+			result = append(result, &board)
+		}
+	}
+	return result, nil
+}
+
+// GetBoardMembers 返回板块成分股
+func (f *FixtureBoardSource) GetBoardMembers(ctx context.Context, boardCode string) ([]string, error) {
+	boards, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range boards {
+		if b.Code == boardCode {
+			return append([]string(nil), b.Members...), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown board: %s", boardCode)
+}
+
+// NewBoardSource 按配置创建板块数据来源：配置了 board_fixture 时读取本地文件，否则使用东方财富
+func NewBoardSource(cfg Config) BoardSource {
+	if cfg.BoardFixture != "" {
+		return NewFixtureBoardSource(cfg.BoardFixture)
+	}
+	return NewEastmoneyDataSource(cfg.Adjust)
+}
+
+// FindBoard 按板块代码或名称查找行业/概念板块，名称完全一致优先
+func FindBoard(ctx context.Context, src BoardSource, key string) (*model.Board, error) {
+	var partial *model.Board
+	for _, typ := range []model.BoardType{model.BoardIndustry, model.BoardConcept} {
+		boards, err := src.ListBoards(ctx, typ)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range boards {
+			if strings.EqualFold(b.Code, key) || b.Name == key {
+				return b, nil
+			}
+			if partial == nil && strings.Contains(b.Name, key) {
+				partial = b
+			}
+		}
+	}
+	if partial != nil {
+		return partial, nil
+	}
+	return nil, fmt.Errorf("未找到板块: %s", key)
+}
+
+// GroupQuery 分组数据请求
+type GroupQuery struct {
+	Board      string          // 板块代码或名称，为空时只使用 Members
+	Members    []string        // 自选股票代码，与板块成分股合并
+	KLineType  model.KLineType // 需要成员K线时的周期，为空时不请求K线
+	KLineCount int
+}
+
+// LoadGroup 获取板块/自选分组的成员行情与K线
+//
+// 部分成员行情或K线获取失败时只记录日志，其余成员照常返回。
+func LoadGroup(ctx context.Context, ds DataSource, boards BoardSource, q GroupQuery) (*model.Group, error) {
+	group := &model.Group{}
+	var codes []string
+	if q.Board != "" {
+		if boards == nil {
+			return nil, fmt.Errorf("未配置板块数据来源")
+		}
+		board, err := FindBoard(ctx, boards, q.Board)
+		if err != nil {
+			return nil, err
+		}
+		members := board.Members
+		if len(members) == 0 {
+			if members, err = boards.GetBoardMembers(ctx, board.Code); err != nil {
+				return nil, fmt.Errorf("获取板块 %s 成分股失败: %w", board.Name, err)
+			}
+		}
+		group.Code, group.Name, group.Board = board.Code, board.Name, board
+		codes = append(codes, members...)
+	}
+
+	seen := make(map[string]bool, len(codes)+len(q.Members))
+	unique := codes[:0]
+	for _, code := range append(codes, q.Members...) {
+		key := code
+		if sec, err := security.Parse(code); err == nil {
+			key = sec.Canonical()
+		}
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, key)
+		}
+	}
+	if len(unique) == 0 {
+		return nil, fmt.Errorf("分组没有成员")
+	}
+	if group.Name == "" {
+		group.Name = fmt.Sprintf("自选%d只", len(unique))
+	}
+
+	stocks, err := ds.GetRealTimeQuote(ctx, unique)
+	if err != nil {
+		if len(stocks) == 0 {
+			return nil, err
+		}
+		slog.Warn("部分成员行情获取失败", "group", group.Name, "error", err)
+	}
+	group.Members = stocks
+
+	if q.KLineType != "" {
+		group.KLines = make(map[string]*model.KLineData, len(stocks))
+		for _, st := range stocks {
+			data, err := ds.GetKLine(ctx, st.Code, q.KLineType, q.KLineCount)
+			if err != nil {
+				slog.Warn("获取成员K线失败", "group", group.Name, "code", st.Code, "error", err)
+				continue
+			}
+			group.KLines[st.Code] = data
+		}
+	}
+	return group, nil
+}
@@ -0,0 +1,106 @@
+package datasource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"stock-monitor/internal/model"
+)
+
+func TestEastmoneyBoards(t *testing.T) {
+	boards := readFixture(t, "eastmoney_boards.json")
+	members := readFixture(t, "eastmoney_board_members.json")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch fs := r.URL.Query().Get("fs"); fs {
+		case eastmoneyIndustryFilter:
+			w.Write(boards)
+		case "b:BK1036":
+			w.Write(members)
+		default:
+			t.Errorf("unexpected fs: %s", fs)
+			w.Write([]byte(`{"rc":0,"data":null}`))
+		}
+	}))
+	defer srv.Close()
+
+	e := NewEastmoneyDataSource(model.AdjustNone)
+	e.listURL = srv.URL
+	ctx := context.Background()
+
+	list, err := e.ListBoards(ctx, model.BoardIndustry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("got %d boards, want 2", len(list))
+	}
+	if b := list[0]; b.Code != "BK1036" || b.Name != "半导体" || b.ChangePercent != 3.41 || b.Up != 152 {
+		t.Errorf("board = %+v", b)
+	}
+	if b := list[1]; b.Price != 0 || b.ChangePercent != 0 {
+		t.Errorf("board without quote = %+v", b)
+	}
+
+	codes, err := e.GetBoardMembers(ctx, "BK1036")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"688981", "002371", "603986"}; !reflect.DeepEqual(codes, want) {
+		t.Errorf("members = %v, want %v", codes, want)
+	}
+}
+
+func TestFixtureBoardSourceAndLoadGroup(t *testing.T) {
+	src := NewFixtureBoardSource("testdata/boards.json")
+	ctx := context.Background()
+
+	board, err := FindBoard(ctx, src, "新能源")
+	if err != nil || board.Code != "BK0493" {
+		t.Fatalf("FindBoard = %+v, %v", board, err)
+	}
+
+	inner := &quoteMapDataSource{prices: map[string]float64{"688981": 50, "002371": 300, "603986": 90, "600519": 1700}}
+	group, err := LoadGroup(ctx, inner, src, GroupQuery{
+		Board:      "BK1036",
+		Members:    []string{"sh600519", "603986"},
+		KLineType:  model.KLineDaily,
+		KLineCount: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.Name != "半导体" || group.ChangePercent() != 3.41 {
+		t.Errorf("group = %s %.2f", group.Name, group.ChangePercent())
+	}
+	if len(group.Members) != 4 || len(group.KLines) != 4 {
+		t.Errorf("got %d members, %d klines, want 4", len(group.Members), len(group.KLines))
+	}
+
+	if _, err := LoadGroup(ctx, inner, src, GroupQuery{Board: "不存在"}); err == nil {
+		t.Error("expected error for unknown board")
+	}
+}
+
+func TestFixtureBoardSourceReturnsCopies(t *testing.T) {
+	src := NewFixtureBoardSource("testdata/boards.json")
+	ctx := context.Background()
+
+	boards, err := src.ListBoards(ctx, model.BoardIndustry)
+	if err != nil || len(boards) == 0 {
+		t.Fatalf("boards = %v, err = %v", boards, err)
+	}
+	boards[0].Members[0] = "changed"
+	boards[0].Members = append(boards[0].Members, "600519")
+
+	again, _ := src.ListBoards(ctx, model.BoardIndustry)
+	if again[0].Members[0] != "688981" || len(again[0].Members) != 3 {
+		t.Errorf("cached members modified: %v", again[0].Members)
+	}
+	members, _ := src.GetBoardMembers(ctx, "BK1036")
+	if members[0] != "688981" {
+		t.Errorf("GetBoardMembers = %v", members)
+	}
+}
@@ -0,0 +1,277 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+)
+
+// CacheOptions K线缓存配置
+type CacheOptions struct {
+	TTL     time.Duration // 缓存在该时间内直接返回，不请求数据源
+	MaxBars int           // 每个序列最多缓存的K线数
+	Dir     string        // 持久化目录，为空时只缓存在内存
+}
+
+// DefaultCacheOptions 默认配置
+func DefaultCacheOptions() CacheOptions {
+	return CacheOptions{
+		TTL:     30 * time.Second,
+		MaxBars: 1000,
+	}
+}
+
+// CacheStats K线缓存统计
+type CacheStats struct {
+	Hits        int64 `json:"hits"`        // 直接命中缓存
+	Incremental int64 `json:"incremental"` // 只拉取缓存之后的新K线
+	Misses      int64 `json:"misses"`      // 全量拉取
+	Errors      int64 `json:"errors"`
+}
+
+// klineSeries 单个 (代码, 周期) 的缓存K线
+type klineSeries struct {
+	sem chan struct{} // 同一序列同时只有一个请求访问数据源，其余等待后读缓存
+
+	data    *model.KLineData
+	fetched time.Time
+	loaded  bool // 已尝试从磁盘加载
+}
+
+// CachedDataSource K线缓存数据源
+//
+// 多条规则请求同一 (代码, 周期) 时共享一份K线；缓存过期后只拉取最后一根缓存K线之后的部分，
+// 与缓存重叠的K线价格不一致（如除权后前复权价格整体变化）时改为全量拉取。
+type CachedDataSource struct {
+	DataSource
+	opts CacheOptions
+	now  func() time.Time
+
+	mu     sync.Mutex
+	series map[string]*klineSeries
+
+	hits, incremental, misses, errors atomic.Int64
+}
+
+// NewCachedDataSource 创建K线缓存数据源
+func NewCachedDataSource(ds DataSource, opts CacheOptions) *CachedDataSource {
+	def := DefaultCacheOptions()
+	if opts.TTL <= 0 {
+		opts.TTL = def.TTL
+	}
+	if opts.MaxBars <= 0 {
+		opts.MaxBars = def.MaxBars
+	}
+	return &CachedDataSource{
+		DataSource: ds,
+		opts:       opts,
+		now:        time.Now,
+		series:     make(map[string]*klineSeries),
+	}
+}
+
+// Stats 返回缓存统计
+func (c *CachedDataSource) Stats() CacheStats {
+	return CacheStats{
+		Hits:        c.hits.Load(),
+		Incremental: c.incremental.Load(),
+		Misses:      c.misses.Load(),
+		Errors:      c.errors.Load(),
+	}
+}
+
+// ListSecurities 透传内部数据源的证券列表
+func (c *CachedDataSource) ListSecurities(ctx context.Context) ([]security.Entry, error) {
+	return listSecurities(ctx, c.DataSource)
+}
+
+func (c *CachedDataSource) getSeries(code string, ktype model.KLineType) *klineSeries {
+	key := code + "_" + string(ktype)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.series[key]
+	if !ok {
+		s = &klineSeries{sem: make(chan struct{}, 1)}
+		c.series[key] = s
+	}
+	return s
+}
+
+// GetKLine 获取K线数据，返回的数据为缓存的副本
+func (c *CachedDataSource) GetKLine(ctx context.Context, code string, ktype model.KLineType, count int) (*model.KLineData, error) {
+	s := c.getSeries(code, ktype)
+	select {
+	case s.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-s.sem }()
+
+	if !s.loaded {
+		s.loaded = true
+		s.data, s.fetched = c.load(code, ktype)
+	}
+
+	now := c.now()
+	if s.data != nil && len(s.data.Lines) >= count && now.Sub(s.fetched) < c.opts.TTL {
+		c.hits.Add(1)
+		return c.snapshot(s.data, count, now), nil
+	}
+
+	data, err := c.refresh(ctx, s.data, code, ktype, count, now)
+	if err != nil {
+		c.errors.Add(1)
+		return nil, err
+	}
+	if n := len(data.Lines); n > c.opts.MaxBars && n > count {
+		data.Lines = data.Lines[n-max(c.opts.MaxBars, count):]
+	}
+	s.data, s.fetched = data, now
+	c.save(data)
+	return c.snapshot(data, count, now), nil
+}
+
+// refresh 增量或全量拉取K线，返回合并后的完整序列
+func (c *CachedDataSource) refresh(ctx context.Context, cached *model.KLineData, code string, ktype model.KLineType, count int, now time.Time) (*model.KLineData, error) {
+	if cached != nil && len(cached.Lines) >= count {
+		tail := cached.Lines[len(cached.Lines)-1].Time
+		if n := barsSince(tail, ktype, now, security.MarketOf(code)) + 2; n < count {
+			fresh, err := c.DataSource.GetKLine(ctx, code, ktype, n)
+			if err != nil {
+				return nil, err
+			}
+			if merged, ok := mergeKLines(cached, fresh); ok {
+				c.incremental.Add(1)
+				return merged, nil
+			}
+		}
+	}
+
+	c.misses.Add(1)
+	return c.DataSource.GetKLine(ctx, code, ktype, count)
+}
+
+// mergeKLines 用新拉取的K线替换缓存中相同时间及之后的部分
+//
+// 新数据需与缓存至少重叠一根已完成K线且价格一致，否则返回 false。
+func mergeKLines(cached, fresh *model.KLineData) (*model.KLineData, bool) {
+	if len(fresh.Lines) < 2 || fresh.Adjust != cached.Adjust {
+		return nil, false
+	}
+	first := fresh.Lines[0]
+	idx := -1
+	for i := len(cached.Lines) - 1; i >= 0; i-- {
+		if cached.Lines[i].Time.Equal(first.Time) {
+			idx = i
+			break
+		}
+		if cached.Lines[i].Time.Before(first.Time) {
+			break
+		}
+	}
+	if idx < 0 || idx == len(cached.Lines)-1 && cached.Forming {
+		return nil, false
+	}
+	if old := cached.Lines[idx]; old.Close != first.Close || old.Open != first.Open {
+		return nil, false
+	}
+
+	merged := *fresh
+	merged.Lines = make([]model.KLine, 0, idx+len(fresh.Lines))
+	merged.Lines = append(merged.Lines, cached.Lines[:idx]...)
+	merged.Lines = append(merged.Lines, fresh.Lines...)
+	return &merged, true
+}
+
+// snapshot 复制最后 count 根K线，并按当前时间重新标记未完成K线
+func (c *CachedDataSource) snapshot(data *model.KLineData, count int, now time.Time) *model.KLineData {
+	lines := data.Lines
+	if count > 0 && len(lines) > count {
+		lines = lines[len(lines)-count:]
+	}
+	result := *data
+	result.Lines = append([]model.KLine(nil), lines...)
+	markForming(&result, now)
+	return &result
+}
+
+// barsSince 估算自 tail 以来新增的K线数（按工作日与交易时段计，不扣除节假日）
+func barsSince(tail time.Time, ktype model.KLineType, now time.Time, market *security.Market) int {
+	if !now.After(tail) {
+		return 0
+	}
+	if minutes := ktype.Minutes(); minutes > 0 {
+		return market.MinutesBetween(tail, now)/minutes + 1
+	}
+
+	days := 0
+	for d := tail.AddDate(0, 0, 1); !d.After(now); d = d.AddDate(0, 0, 1) {
+		if market.IsTradingDay(d) {
+			days++
+		}
+	}
+	switch ktype {
+	case model.KLineWeekly:
+		return days/5 + 1
+	case model.KLineMonthly:
+		return days/20 + 1
+	}
+	return days
+}
+
+func (c *CachedDataSource) path(code string, ktype model.KLineType) string {
+	return filepath.Join(c.opts.Dir, fmt.Sprintf("%s_%s.json", code, ktype))
+}
+
+// cacheFile 持久化的K线缓存
+type cacheFile struct {
+	Fetched time.Time        `json:"fetched"`
+	Data    *model.KLineData `json:"data"`
+}
+
+// load 从磁盘加载缓存，失败时视为无缓存
+func (c *CachedDataSource) load(code string, ktype model.KLineType) (*model.KLineData, time.Time) {
+	if c.opts.Dir == "" {
+		return nil, time.Time{}
+	}
+	raw, err := os.ReadFile(c.path(code, ktype))
+	if err != nil {
+		return nil, time.Time{}
+	}
+	var f cacheFile
+	if err := json.Unmarshal(raw, &f); err != nil || f.Data == nil {
+		slog.Warn("K线缓存文件无效", "code", code, "type", ktype, "error", err)
+		return nil, time.Time{}
+	}
+	return f.Data, f.Fetched
+}
+
+// save 写入磁盘缓存，先写临时文件再重命名
+func (c *CachedDataSource) save(data *model.KLineData) {
+	if c.opts.Dir == "" {
+		return
+	}
+	raw, err := json.Marshal(cacheFile{Fetched: c.now(), Data: data})
+	if err == nil {
+		err = os.MkdirAll(c.opts.Dir, 0755)
+	}
+	path := c.path(data.Code, data.Type)
+	if err == nil {
+		err = os.WriteFile(path+".tmp", raw, 0644)
+	}
+	if err == nil {
+		err = os.Rename(path+".tmp", path)
+	}
+	if err != nil {
+		slog.Warn("保存K线缓存失败", "path", path, "error", err)
+	}
+}
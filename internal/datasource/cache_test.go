@@ -0,0 +1,151 @@
+package datasource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+)
+
+func TestMergeKLines(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.Local) }
+	bar := func(d int, c float64) model.KLine { return model.KLine{Time: day(d), Open: 10, Close: c} }
+
+	tests := []struct {
+		name      string
+		cached    []model.KLine
+		forming   bool
+		fresh     []model.KLine
+		freshAdj  model.AdjustType
+		wantOK    bool
+		wantClose []float64
+	}{
+		{
+			name:   "尾部重叠一根",
+			cached: []model.KLine{bar(2, 1), bar(3, 2), bar(4, 3)},
+			fresh:  []model.KLine{bar(4, 3), bar(5, 4)},
+			wantOK: true, wantClose: []float64{1, 2, 3, 4},
+		},
+		{
+			name:    "形成中的K线被覆盖",
+			cached:  []model.KLine{bar(2, 1), bar(3, 2), bar(4, 2.5)},
+			forming: true,
+			fresh:   []model.KLine{bar(3, 2), bar(4, 3), bar(5, 4)},
+			wantOK:  true, wantClose: []float64{1, 2, 3, 4},
+		},
+		{
+			name:    "只与形成中的K线重叠",
+			cached:  []model.KLine{bar(2, 1), bar(3, 2), bar(4, 2.5)},
+			forming: true,
+			fresh:   []model.KLine{bar(4, 3), bar(5, 4)},
+		},
+		{
+			name:   "重叠K线价格变化（除权）",
+			cached: []model.KLine{bar(2, 1), bar(3, 2)},
+			fresh:  []model.KLine{bar(3, 1.8), bar(4, 2)},
+		},
+		{
+			name:   "没有重叠",
+			cached: []model.KLine{bar(2, 1), bar(3, 2)},
+			fresh:  []model.KLine{bar(5, 3), bar(8, 4)},
+		},
+		{
+			name:     "复权方式不同",
+			cached:   []model.KLine{bar(2, 1), bar(3, 2)},
+			fresh:    []model.KLine{bar(3, 2), bar(4, 3)},
+			freshAdj: model.AdjustHFQ,
+		},
+		{
+			name:   "新数据不足两根",
+			cached: []model.KLine{bar(2, 1), bar(3, 2)},
+			fresh:  []model.KLine{bar(3, 2)},
+		},
+	}
+	for _, tt := range tests {
+		cached := &model.KLineData{Code: "600519", Type: model.KLineDaily, Lines: tt.cached, Forming: tt.forming}
+		fresh := &model.KLineData{Code: "600519", Type: model.KLineDaily, Lines: tt.fresh, Adjust: tt.freshAdj}
+		merged, ok := mergeKLines(cached, fresh)
+		if ok != tt.wantOK {
+			t.Errorf("%s: ok = %v, want %v", tt.name, ok, tt.wantOK)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if len(merged.Lines) != len(tt.wantClose) {
+			t.Errorf("%s: got %d lines, want %d", tt.name, len(merged.Lines), len(tt.wantClose))
+			continue
+		}
+		for i, c := range tt.wantClose {
+			if merged.Lines[i].Close != c {
+				t.Errorf("%s: line %d close = %v, want %v", tt.name, i, merged.Lines[i].Close, c)
+			}
+		}
+	}
+}
+
+// seriesSource 按 count 返回日K序列最后若干根，并记录请求的数量
+type seriesSource struct {
+	stubDataSource
+	lines    []model.KLine
+	requests []int
+}
+
+func (s *seriesSource) GetKLine(ctx context.Context, code string, ktype model.KLineType, count int) (*model.KLineData, error) {
+	s.requests = append(s.requests, count)
+	lines := s.lines
+	if len(lines) > count {
+		lines = lines[len(lines)-count:]
+	}
+	return &model.KLineData{Code: code, Type: ktype, Lines: append([]model.KLine(nil), lines...)}, nil
+}
+
+func TestCachedDataSourceIncremental(t *testing.T) {
+	loc := security.MarketCN.Location
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, loc) }
+	inner := &seriesSource{}
+	for d := 2; d <= 8; d++ {
+		if wd := day(d).Weekday(); wd != time.Saturday && wd != time.Sunday {
+			inner.lines = append(inner.lines, model.KLine{Time: day(d), Open: float64(d), Close: float64(d)})
+		}
+	}
+
+	c := NewCachedDataSource(inner, CacheOptions{TTL: time.Minute})
+	now := time.Date(2024, 1, 8, 16, 0, 0, 0, loc)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	steps := []struct {
+		name      string
+		advance   time.Duration
+		appendDay int
+		wantReq   []int
+		wantLast  float64
+		wantStats CacheStats
+	}{
+		{"首次全量", 0, 0, []int{5}, 8, CacheStats{Misses: 1}},
+		{"有效期内命中", 30 * time.Second, 0, []int{5}, 8, CacheStats{Misses: 1, Hits: 1}},
+		{"过期后增量", 24 * time.Hour, 9, []int{5, 3}, 9, CacheStats{Misses: 1, Hits: 1, Incremental: 1}},
+	}
+	for _, s := range steps {
+		now = now.Add(s.advance)
+		if s.appendDay > 0 {
+			inner.lines = append(inner.lines, model.KLine{Time: day(s.appendDay), Open: float64(s.appendDay), Close: float64(s.appendDay)})
+		}
+		data, err := c.GetKLine(ctx, "600519", model.KLineDaily, 5)
+		if err != nil {
+			t.Fatalf("%s: %v", s.name, err)
+		}
+		if len(data.Lines) != 5 || data.Lines[4].Close != s.wantLast {
+			t.Errorf("%s: lines = %+v", s.name, data.Lines)
+		}
+		if len(inner.requests) != len(s.wantReq) || inner.requests[len(inner.requests)-1] != s.wantReq[len(s.wantReq)-1] {
+			t.Errorf("%s: requests = %v, want %v", s.name, inner.requests, s.wantReq)
+		}
+		if got := c.Stats(); got != s.wantStats {
+			t.Errorf("%s: stats = %+v, want %+v", s.name, got, s.wantStats)
+		}
+	}
+}
@@ -0,0 +1,382 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+)
+
+// CompositeOptions 组合数据源配置
+type CompositeOptions struct {
+	FailureThreshold int           // 连续失败该次数后熔断
+	Cooldown         time.Duration // 熔断持续时间，之后放行一次试探请求
+	Quorum           bool          // 仲裁模式：同时请求所有可用数据源并交叉校验行情
+	Tolerance        float64       // 仲裁模式下允许的价格偏差 %
+}
+
+// DefaultCompositeOptions 默认配置
+func DefaultCompositeOptions() CompositeOptions {
+	return CompositeOptions{
+		FailureThreshold: 3,
+		Cooldown:         time.Minute,
+		Tolerance:        0.5,
+	}
+}
+
+// SourceHealth 数据源健康状态
+type SourceHealth struct {
+	Name      string    `json:"name"`
+	Healthy   bool      `json:"healthy"`    // 熔断器是否闭合
+	Failures  int       `json:"failures"`   // 连续失败次数
+	OpenUntil time.Time `json:"open_until"` // 熔断截止时间
+	LastError string    `json:"last_error,omitempty"`
+	Requests  int64     `json:"requests"`
+	Errors    int64     `json:"errors"`
+}
+
+// sourceState 单个数据源的熔断状态
+type sourceState struct {
+	ds DataSource
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool // 半开状态下已放行试探请求
+	lastErr   error
+	requests  int64
+	errors    int64
+}
+
+// allow 熔断器是否放行请求
+func (s *sourceState) allow(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.openUntil.IsZero() {
+		return true
+	}
+	if now.Before(s.openUntil) || s.probing {
+		return false
+	}
+	s.probing = true
+	return true
+}
+
+func (s *sourceState) record(err error, opts CompositeOptions, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	s.probing = false
+	if err == nil {
+		s.failures = 0
+		s.openUntil = time.Time{}
+		return
+	}
+	s.errors++
+	s.failures++
+	s.lastErr = err
+	if s.failures >= opts.FailureThreshold {
+		s.openUntil = now.Add(opts.Cooldown)
+		slog.Warn("数据源熔断", "source", s.ds.Name(), "failures", s.failures, "until", s.openUntil, "error", err)
+	}
+}
+
+// CompositeDataSource 组合数据源：按优先级故障转移，可选多源仲裁
+type CompositeDataSource struct {
+	sources []*sourceState
+	lists   []*sourceState // 证券列表单独熔断，列表接口故障不影响行情
+	opts    CompositeOptions
+	now     func() time.Time
+}
+
+// NewCompositeDataSource 创建组合数据源，sources 按优先级排列
+func NewCompositeDataSource(opts CompositeOptions, sources ...DataSource) *CompositeDataSource {
+	def := DefaultCompositeOptions()
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = def.FailureThreshold
+	}
+	if opts.Cooldown <= 0 {
+		opts.Cooldown = def.Cooldown
+	}
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = def.Tolerance
+	}
+
+	c := &CompositeDataSource{opts: opts, now: time.Now}
+	for _, ds := range sources {
+		c.sources = append(c.sources, &sourceState{ds: ds})
+		c.lists = append(c.lists, &sourceState{ds: ds})
+	}
+	return c
+}
+
+func (c *CompositeDataSource) Name() string {
+	return "composite"
+}
+
+// Health 返回各数据源健康状态
+func (c *CompositeDataSource) Health() []SourceHealth {
+	now := c.now()
+	result := make([]SourceHealth, len(c.sources))
+	for i, s := range c.sources {
+		s.mu.Lock()
+		h := SourceHealth{
+			Name:      s.ds.Name(),
+			Healthy:   s.openUntil.IsZero() || !now.Before(s.openUntil),
+			Failures:  s.failures,
+			OpenUntil: s.openUntil,
+			Requests:  s.requests,
+			Errors:    s.errors,
+		}
+		if s.lastErr != nil {
+			h.LastError = s.lastErr.Error()
+		}
+		s.mu.Unlock()
+		result[i] = h
+	}
+	return result
+}
+
+// GetRealTimeQuote 获取实时行情
+func (c *CompositeDataSource) GetRealTimeQuote(ctx context.Context, codes []string) ([]*model.Stock, error) {
+	if c.opts.Quorum {
+		return c.quorumQuote(ctx, codes)
+	}
+
+	// 部分代码失败时，剩余代码交给下一个数据源
+	var result []*model.Stock
+	var errs []error
+	var pending QuoteErrors
+	for _, s := range c.sources {
+		if !s.allow(c.now()) {
+			continue
+		}
+		stocks, err := s.ds.GetRealTimeQuote(ctx, codes)
+		var partial QuoteErrors
+		if errors.As(err, &partial) {
+			s.record(partial.sourceError(), c.opts, c.now())
+			result = append(result, stocks...)
+			pending = partial
+			codes = partial.Codes()
+			continue
+		}
+		s.record(err, c.opts, c.now())
+		if err == nil {
+			return append(result, stocks...), nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", s.ds.Name(), err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if len(result) > 0 {
+		return result, pending
+	}
+	return nil, c.allFailed(errs)
+}
+
+// ListSecurities 从第一个支持证券列表的可用数据源获取
+func (c *CompositeDataSource) ListSecurities(ctx context.Context) ([]security.Entry, error) {
+	var errs []error
+	for _, s := range c.lists {
+		lister, ok := s.ds.(SecurityLister)
+		if !ok || !s.allow(c.now()) {
+			continue
+		}
+		entries, err := lister.ListSecurities(ctx)
+		s.record(err, c.opts, c.now())
+		if err == nil {
+			return entries, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", s.ds.Name(), err))
+	}
+	return nil, c.allFailed(errs)
+}
+
+// GetKLine 获取K线数据，按优先级故障转移
+func (c *CompositeDataSource) GetKLine(ctx context.Context, code string, ktype model.KLineType, count int) (*model.KLineData, error) {
+	var errs []error
+	for _, s := range c.sources {
+		if !s.allow(c.now()) {
+			continue
+		}
+		data, err := s.ds.GetKLine(ctx, code, ktype, count)
+		s.record(err, c.opts, c.now())
+		if err == nil {
+			return data, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", s.ds.Name(), err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, c.allFailed(errs)
+}
+
+func (c *CompositeDataSource) allFailed(errs []error) error {
+	if len(errs) == 0 {
+		return fmt.Errorf("没有可用的数据源（全部熔断）")
+	}
+	return fmt.Errorf("所有数据源均失败: %w", errors.Join(errs...))
+}
+
+// quorumQuote 并发请求所有可用数据源，以优先级最高的结果为准，
+// 与其他数据源价格偏差超过容差的行情将被剔除，并以 QuoteErrors 返回原因
+func (c *CompositeDataSource) quorumQuote(ctx context.Context, codes []string) ([]*model.Stock, error) {
+	results := make([][]*model.Stock, len(c.sources))
+	partials := make([]QuoteErrors, len(c.sources))
+	errs := make([]error, len(c.sources))
+
+	var wg sync.WaitGroup
+	for i, s := range c.sources {
+		if !s.allow(c.now()) {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, s *sourceState) {
+			defer wg.Done()
+			stocks, err := s.ds.GetRealTimeQuote(ctx, codes)
+			var partial QuoteErrors
+			if errors.As(err, &partial) {
+				// 部分失败仍参与仲裁，请求失败的代码计入熔断
+				s.record(partial.sourceError(), c.opts, c.now())
+				results[i], partials[i] = stocks, partial
+				return
+			}
+			s.record(err, c.opts, c.now())
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", s.ds.Name(), err)
+				return
+			}
+			results[i], partials[i] = stocks, partial
+		}(i, s)
+	}
+	wg.Wait()
+
+	// 按优先级合并：每个代码取第一个返回它的数据源，其余用于校验
+	primary := make(map[string]*model.Stock)
+	var order []string
+	others := make(map[string][]*model.Stock)
+	responded := false
+	for i, stocks := range results {
+		if errs[i] != nil || stocks == nil {
+			continue
+		}
+		responded = true
+		for _, st := range stocks {
+			if _, ok := primary[st.Code]; !ok {
+				primary[st.Code] = st
+				order = append(order, st.Code)
+			} else {
+				others[st.Code] = append(others[st.Code], st)
+			}
+		}
+	}
+	if !responded {
+		var failed []error
+		for _, err := range errs {
+			if err != nil {
+				failed = append(failed, err)
+			}
+		}
+		return nil, c.allFailed(failed)
+	}
+
+	// 失败原因以请求时的原始代码为键
+	requested := make(map[string]string, len(codes))
+	for _, code := range codes {
+		if sec, err := security.Parse(code); err == nil {
+			requested[sec.Canonical()] = code
+		}
+	}
+
+	var failed QuoteErrors
+	stocks := make([]*model.Stock, 0, len(order))
+	for _, code := range order {
+		st := primary[code]
+		if other, ok := c.disagree(st, others[code]); ok {
+			slog.Warn("数据源行情不一致，已剔除", "code", code, "price", st.Price, "other_price", other.Price)
+			if failed == nil {
+				failed = QuoteErrors{}
+			}
+			key := code
+			if orig, ok := requested[code]; ok {
+				key = orig
+			}
+			failed[key] = &CodeError{
+				Code:   key,
+				Status: model.QuoteInvalid,
+				Reason: fmt.Sprintf("数据源价格不一致 %.3f / %.3f", st.Price, other.Price),
+			}
+			continue
+		}
+		stocks = append(stocks, st)
+	}
+
+	// 所有数据源都没有返回的代码，沿用优先级最高的数据源给出的原因
+	for _, partial := range partials {
+		for code, err := range partial {
+			if sec, perr := security.Parse(code); perr == nil && primary[sec.Canonical()] != nil {
+				continue
+			}
+			if failed == nil {
+				failed = QuoteErrors{}
+			}
+			if _, ok := failed[code]; !ok {
+				failed[code] = err
+			}
+		}
+	}
+
+	if failed == nil {
+		return stocks, nil
+	}
+	if len(stocks) == 0 {
+		return nil, failed
+	}
+	return stocks, failed
+}
+
+// disagree 返回与基准行情价格偏差超过容差的行情，停牌或无成交价的行情不参与比较
+func (c *CompositeDataSource) disagree(st *model.Stock, others []*model.Stock) (*model.Stock, bool) {
+	if !priceComparable(st) {
+		return nil, false
+	}
+	for _, o := range others {
+		if !priceComparable(o) {
+			continue
+		}
+		base := math.Max(math.Abs(st.Price), math.Abs(o.Price))
+		if math.Abs(st.Price-o.Price)/base*100 > c.opts.Tolerance {
+			return o, true
+		}
+	}
+	return nil, false
+}
+
+// priceComparable 行情价格是否可用于仲裁：停牌或尚无成交时价格为零
+func priceComparable(st *model.Stock) bool {
+	return st.Status != model.QuoteSuspended && st.Price > 0
+}
+
+// sourceError 部分失败中属于数据源自身故障（请求失败、数据格式异常）的部分，
+// 停牌、退市、无该代码等不计入熔断
+func (e QuoteErrors) sourceError() error {
+	failed := QuoteErrors{}
+	for code, err := range e {
+		var ce *CodeError
+		if !errors.As(err, &ce) || ce.Status == model.QuoteError || ce.Status == model.QuoteMalformed {
+			failed[code] = err
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return failed
+}
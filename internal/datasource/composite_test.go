@@ -0,0 +1,233 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+)
+
+// fakeSource 可控制失败的数据源
+type fakeSource struct {
+	name    string
+	prices  map[string]float64
+	err     error
+	listErr error
+	missing model.QuoteStatus // 不在 prices 中的代码的失败状态，默认无该代码
+	calls   int
+}
+
+func (f *fakeSource) Name() string { return f.name }
+
+func (f *fakeSource) GetRealTimeQuote(ctx context.Context, codes []string) ([]*model.Stock, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	var stocks []*model.Stock
+	failed := QuoteErrors{}
+	for _, code := range codes {
+		if p, ok := f.prices[code]; ok {
+			stocks = append(stocks, &model.Stock{Code: code, Price: p})
+		} else {
+			status := f.missing
+			if status == "" {
+				status = model.QuoteUnknown
+			}
+			failed[code] = &CodeError{Code: code, Status: status}
+		}
+	}
+	if len(failed) > 0 {
+		return stocks, failed
+	}
+	return stocks, nil
+}
+
+func (f *fakeSource) GetKLine(ctx context.Context, code string, ktype model.KLineType, count int) (*model.KLineData, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &model.KLineData{Code: code, Type: ktype}, nil
+}
+
+func (f *fakeSource) ListSecurities(ctx context.Context) ([]security.Entry, error) {
+	return nil, f.listErr
+}
+
+func TestCompositeCircuitBreaker(t *testing.T) {
+	primary := &fakeSource{name: "primary", prices: map[string]float64{"600519": 1700}, err: errors.New("timeout")}
+	backup := &fakeSource{name: "backup", prices: map[string]float64{"600519": 1701}}
+	c := NewCompositeDataSource(CompositeOptions{FailureThreshold: 3, Cooldown: time.Minute}, primary, backup)
+	now := time.Date(2024, 1, 3, 10, 0, 0, 0, time.Local)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	quote := func() float64 {
+		t.Helper()
+		stocks, err := c.GetRealTimeQuote(ctx, []string{"600519"})
+		if err != nil || len(stocks) != 1 {
+			t.Fatalf("stocks = %v, err = %v", stocks, err)
+		}
+		return stocks[0].Price
+	}
+
+	steps := []struct {
+		name        string
+		advance     time.Duration
+		primaryErr  error
+		wantPrice   float64
+		wantCalls   int // 本步骤结束时 primary 的累计调用次数
+		wantHealthy bool
+	}{
+		{"首次失败转移", 0, errors.New("timeout"), 1701, 1, true},
+		{"第二次失败", 0, errors.New("timeout"), 1701, 2, true},
+		{"第三次失败熔断", 0, errors.New("timeout"), 1701, 3, false},
+		{"冷却期内跳过", 30 * time.Second, nil, 1701, 3, false},
+		{"半开试探失败重新熔断", time.Minute, errors.New("timeout"), 1701, 4, false},
+		{"重新熔断后跳过", 0, nil, 1701, 4, false},
+		{"半开试探成功恢复", time.Minute, nil, 1700, 5, true},
+		{"恢复后优先使用", 0, nil, 1700, 6, true},
+	}
+	for _, s := range steps {
+		now = now.Add(s.advance)
+		primary.err = s.primaryErr
+		if got := quote(); got != s.wantPrice {
+			t.Errorf("%s: price = %v, want %v", s.name, got, s.wantPrice)
+		}
+		if primary.calls != s.wantCalls {
+			t.Errorf("%s: primary calls = %d, want %d", s.name, primary.calls, s.wantCalls)
+		}
+		if h := c.Health()[0]; h.Healthy != s.wantHealthy {
+			t.Errorf("%s: healthy = %v, want %v", s.name, h.Healthy, s.wantHealthy)
+		}
+	}
+}
+
+func TestCompositeHalfOpenSingleProbe(t *testing.T) {
+	s := &sourceState{ds: &fakeSource{name: "x"}}
+	opts := CompositeOptions{FailureThreshold: 1, Cooldown: time.Minute}
+	now := time.Now()
+	s.record(errors.New("down"), opts, now)
+
+	if s.allow(now.Add(time.Second)) {
+		t.Error("allowed during cooldown")
+	}
+	later := now.Add(2 * time.Minute)
+	if !s.allow(later) {
+		t.Fatal("probe not allowed after cooldown")
+	}
+	if s.allow(later) {
+		t.Error("second request allowed while probing")
+	}
+	s.record(nil, opts, later)
+	if !s.allow(later) {
+		t.Error("not closed after successful probe")
+	}
+}
+
+func TestCompositeQuorum(t *testing.T) {
+	tests := []struct {
+		name       string
+		primary    float64
+		secondary  float64
+		wantStatus model.QuoteStatus
+	}{
+		{"一致", 100, 100, model.QuoteOK},
+		{"容差内", 100, 100.4, model.QuoteOK},
+		{"超出容差", 100, 101, model.QuoteInvalid},
+		{"均无成交价", 0, 0, model.QuoteOK},
+		{"主数据源无成交价", 0, 100, model.QuoteOK},
+		{"次要数据源无成交价", 100, 0, model.QuoteOK},
+	}
+	for _, tt := range tests {
+		a := &fakeSource{name: "a", prices: map[string]float64{"600519": tt.primary}}
+		b := &fakeSource{name: "b", prices: map[string]float64{"600519": tt.secondary, "000001": 10}}
+		c := NewCompositeDataSource(CompositeOptions{Quorum: true, Tolerance: 0.5}, a, b)
+
+		codes := []string{"600519", "000001", "000002"}
+		stocks, err := c.GetRealTimeQuote(context.Background(), codes)
+		statuses := QuoteStatuses(codes, stocks, err)
+		if statuses["600519"] != tt.wantStatus {
+			t.Errorf("%s: 600519 status = %s, want %s (err %v)", tt.name, statuses["600519"], tt.wantStatus, err)
+		}
+		// 只有次要数据源返回的代码照常使用，均未返回的代码保留原因
+		if statuses["000001"] != model.QuoteOK || statuses["000002"] != model.QuoteUnknown {
+			t.Errorf("%s: statuses = %v", tt.name, statuses)
+		}
+	}
+}
+
+func TestCompositeQuorumSuspended(t *testing.T) {
+	a := &fakeSource{name: "a", prices: map[string]float64{"600519": 1700}}
+	b := &suspendedSource{fakeSource{name: "b", prices: map[string]float64{"600519": 1650}}}
+	c := NewCompositeDataSource(CompositeOptions{Quorum: true, Tolerance: 0.5}, a, b)
+
+	stocks, err := c.GetRealTimeQuote(context.Background(), []string{"600519"})
+	if err != nil || len(stocks) != 1 || stocks[0].Price != 1700 {
+		t.Errorf("stocks = %v, err = %v, want 600519 at 1700", stocks, err)
+	}
+}
+
+// suspendedSource 返回停牌状态的行情，价格为停牌前的收盘价
+type suspendedSource struct{ fakeSource }
+
+func (s *suspendedSource) GetRealTimeQuote(ctx context.Context, codes []string) ([]*model.Stock, error) {
+	stocks, err := s.fakeSource.GetRealTimeQuote(ctx, codes)
+	for _, st := range stocks {
+		st.Status = model.QuoteSuspended
+	}
+	return stocks, err
+}
+
+func TestCompositePartialFailureBreaker(t *testing.T) {
+	tests := []struct {
+		name        string
+		missing     model.QuoteStatus
+		quorum      bool
+		wantHealthy bool
+	}{
+		{"请求失败计入熔断", model.QuoteError, false, false},
+		{"格式异常计入熔断", model.QuoteMalformed, false, false},
+		{"停牌不计入熔断", model.QuoteSuspended, false, true},
+		{"无该代码不计入熔断", model.QuoteUnknown, false, true},
+		{"仲裁模式请求失败计入熔断", model.QuoteError, true, false},
+		{"仲裁模式停牌不计入熔断", model.QuoteSuspended, true, true},
+	}
+	for _, tt := range tests {
+		primary := &fakeSource{name: "primary", prices: map[string]float64{"600519": 1700}, missing: tt.missing}
+		backup := &fakeSource{name: "backup", prices: map[string]float64{"600519": 1700, "000001": 10}}
+		c := NewCompositeDataSource(CompositeOptions{FailureThreshold: 2, Quorum: tt.quorum}, primary, backup)
+
+		for i := 0; i < 2; i++ {
+			stocks, _ := c.GetRealTimeQuote(context.Background(), []string{"600519", "000001"})
+			if len(stocks) != 2 {
+				t.Fatalf("%s: stocks = %v, want both codes", tt.name, stocks)
+			}
+		}
+		h := c.Health()[0]
+		if h.Healthy != tt.wantHealthy {
+			t.Errorf("%s: healthy = %v, want %v (failures %d)", tt.name, h.Healthy, tt.wantHealthy, h.Failures)
+		}
+	}
+}
+
+func TestCompositeListSecuritiesBreaker(t *testing.T) {
+	src := &fakeSource{name: "a", prices: map[string]float64{"600519": 1700}, listErr: errors.New("list down")}
+	c := NewCompositeDataSource(CompositeOptions{FailureThreshold: 1}, src)
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.ListSecurities(context.Background()); err == nil {
+			t.Fatal("expected list error")
+		}
+	}
+	if stocks, err := c.GetRealTimeQuote(context.Background(), []string{"600519"}); err != nil || len(stocks) != 1 {
+		t.Fatalf("quote after list failures: %v, %v", stocks, err)
+	}
+	if !c.Health()[0].Healthy {
+		t.Error("list failures tripped the quote breaker")
+	}
+}
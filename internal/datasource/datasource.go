@@ -2,8 +2,13 @@ package datasource
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
 
 	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
 )
 
 // DataSource 数据源接口
@@ -17,3 +22,153 @@ type DataSource interface {
 	// GetKLine 获取K线数据
 	GetKLine(ctx context.Context, code string, ktype model.KLineType, count int) (*model.KLineData, error)
 }
+
+// SecurityLister 可提供全市场证券列表的数据源
+type SecurityLister interface {
+	ListSecurities(ctx context.Context) ([]security.Entry, error)
+}
+
+// Config 数据源配置，对应 config.yaml 的 datasource 段
+type Config struct {
+	Name         string           `yaml:"name" json:"name"`                   // sina / eastmoney / tencent / local
+	Adjust       model.AdjustType `yaml:"adjust" json:"adjust"`               // K线复权方式
+	FactorDir    string           `yaml:"factor_dir" json:"factor_dir"`       // 复权因子CSV目录
+	Fallback     []string         `yaml:"fallback" json:"fallback"`           // 备用数据源，按优先级排列
+	Quorum       bool             `yaml:"quorum" json:"quorum"`               // 多源仲裁
+	Tolerance    float64          `yaml:"tolerance" json:"tolerance"`         // 仲裁允许的价格偏差 %
+	SecurityList string           `yaml:"security_list" json:"security_list"` // 证券列表离线CSV
+	RealtimeBars bool             `yaml:"realtime_bars" json:"realtime_bars"` // 由行情快照合成当日K线
+	KLineCache   bool             `yaml:"kline_cache" json:"kline_cache"`     // 缓存K线，过期后增量拉取
+	CacheTTL     int              `yaml:"cache_ttl" json:"cache_ttl"`         // K线缓存有效期 秒
+	CacheDir     string           `yaml:"cache_dir" json:"cache_dir"`         // K线缓存持久化目录
+	Record       string           `yaml:"record" json:"record"`               // 录制行情与K线响应到该文件
+	Replay       []string         `yaml:"replay" json:"replay"`               // 回放录制文件，设置后不访问网络
+	LocalDir     string           `yaml:"local_dir" json:"local_dir"`         // 本地K线文件目录（local 数据源）
+	BondTerms    string           `yaml:"bond_terms" json:"bond_terms"`       // 可转债条款CSV，用于计算转股溢价率
+	ETFIOPV      bool             `yaml:"etf_iopv" json:"etf_iopv"`           // 获取ETF估算净值，用于计算溢价率
+	StreamURL    string           `yaml:"stream_url" json:"stream_url"`       // WebSocket 推送行情地址，为空时轮询
+	PollInterval int              `yaml:"poll_interval" json:"poll_interval"` // 轮询推送的请求间隔 秒
+	MaxStale     int              `yaml:"max_stale" json:"max_stale"`         // 交易时段内行情落后超过该分钟数视为过期，0 使用默认值，负数不检查
+	BoardFixture string           `yaml:"board_fixture" json:"board_fixture"` // 本地板块数据JSON，为空时从东方财富获取
+	Fundamentals string           `yaml:"fundamentals" json:"fundamentals"`   // 基本面数据来源: tencent 或本地CSV路径，为空时不提供
+}
+
+// New 按配置创建数据源，配置了备用数据源时返回组合数据源
+//
+// 行情均经过 ValidatingDataSource 校验，零价、过期等异常行情不会返回给调用方。
+// 录制在校验之前进行，被校验剔除的原始行情同样写入录制文件。
+// 行情的品种类型统一由 InstrumentDataSource 补充，各数据源不单独设置。
+//
+// 配置了回放文件时以回放数据源代替网络数据源，回放行情同样经过校验并补充品种类型，其他选项均不生效。
+// 后复权（hfq）不支持，返回错误。
+func New(cfg Config) (DataSource, error) {
+	if len(cfg.Replay) > 0 {
+		replay, err := NewReplayDataSource(nil, cfg.Replay...)
+		if err != nil {
+			return nil, err
+		}
+		// 回放只补充品种类型，不请求转债条款和参考净值
+		return NewInstrumentDataSource(NewValidatingDataSource(replay, validationOptions(cfg)), nil, nil), nil
+	}
+	if cfg.Adjust == model.AdjustHFQ {
+		return nil, errHFQ
+	}
+
+	ds, err := newComposite(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Record != "" {
+		if ds, err = NewRecordingDataSource(ds, cfg.Record); err != nil {
+			return nil, err
+		}
+	}
+	ds = NewValidatingDataSource(ds, validationOptions(cfg))
+	var bonds BondTermsSource
+	var iopv IOPVSource
+	if cfg.BondTerms != "" {
+		bonds = NewCSVBondTermsSource(cfg.BondTerms)
+	}
+	if cfg.ETFIOPV {
+		iopv = NewSinaIOPVSource()
+	}
+	ds = NewInstrumentDataSource(ds, bonds, iopv)
+	if cfg.KLineCache {
+		opts := DefaultCacheOptions()
+		opts.Dir = cfg.CacheDir
+		if cfg.CacheTTL > 0 {
+			opts.TTL = time.Duration(cfg.CacheTTL) * time.Second
+		}
+		ds = NewCachedDataSource(ds, opts)
+	}
+	if cfg.RealtimeBars {
+		ds = NewRealtimeBarDataSource(ds, nil)
+	}
+	return ds, nil
+}
+
+// errHFQ 后复权K线与不复权的实时价格不可比，均线等规则会在错误的价位触发
+var errHFQ = errors.New("datasource.adjust 不支持 hfq：规则用实时价格比较K线，请使用 qfq 或 none")
+
+// validationOptions 由配置生成行情校验参数
+func validationOptions(cfg Config) ValidationOptions {
+	opts := DefaultValidationOptions()
+	if cfg.MaxStale != 0 {
+		opts.MaxStale = cfg.MaxStale
+	}
+	// 本地文件和回放的行情时间为历史时间，不检查过期
+	if len(cfg.Replay) > 0 || cfg.Name == "local" || slices.Contains(cfg.Fallback, "local") {
+		opts.MaxStale = -1
+	}
+	return opts
+}
+
+func newComposite(cfg Config) (DataSource, error) {
+	primary, err := newSource(cfg.Name, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Fallback) == 0 {
+		return primary, nil
+	}
+
+	sources := []DataSource{primary}
+	for _, name := range cfg.Fallback {
+		ds, err := newSource(name, cfg)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, ds)
+	}
+	opts := DefaultCompositeOptions()
+	opts.Quorum = cfg.Quorum
+	if cfg.Tolerance > 0 {
+		opts.Tolerance = cfg.Tolerance
+	}
+	return NewCompositeDataSource(opts, sources...), nil
+}
+
+// newSource 创建单个数据源
+//
+// 东方财富、腾讯接口自带复权；新浪只返回不复权K线，配置了因子目录时由 AdjustedDataSource 复权。
+// 新浪不提供港股美股K线，改由腾讯获取。
+func newSource(name string, cfg Config) (DataSource, error) {
+	switch name {
+	case "", "sina":
+		var ds DataSource = NewSinaDataSource()
+		if cfg.FactorDir != "" && cfg.Adjust != "" && cfg.Adjust != model.AdjustNone {
+			ds = NewAdjustedDataSource(ds, NewCSVFactorSource(cfg.FactorDir), cfg.Adjust)
+		}
+		return NewOverseasKLineDataSource(ds, NewTencentDataSource(cfg.Adjust)), nil
+	case "eastmoney":
+		return NewEastmoneyDataSource(cfg.Adjust), nil
+	case "tencent":
+		return NewTencentDataSource(cfg.Adjust), nil
+	case "local":
+		if cfg.LocalDir == "" {
+			return nil, fmt.Errorf("local datasource requires local_dir")
+		}
+		return NewLocalDataSource(cfg.LocalDir), nil
+	}
+	return nil, fmt.Errorf("unknown datasource: %s", name)
+}
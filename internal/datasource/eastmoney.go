@@ -0,0 +1,384 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+)
+
+const (
+	eastmoneyQuoteURL = "https://push2.eastmoney.com/api/qt/ulist.np/get"
+	eastmoneyKLineURL = "https://push2his.eastmoney.com/api/qt/stock/kline/get"
+	eastmoneyListURL  = "https://push2.eastmoney.com/api/qt/clist/get"
+
+	// 证券列表范围: 深市主板,创业板,沪市主板,科创板,北交所
+	eastmoneyListFilter = "m:0+t:6,m:0+t:80,m:1+t:2,m:1+t:23,m:0+t:81+s:2048"
+	eastmoneyListPage   = 100
+
+	// 行情字段: 代码,市场,名称,最新价,成交量(手),成交额,最高,最低,开盘,昨收,行情时间
+	eastmoneyQuoteFields = "f12,f13,f14,f2,f5,f6,f15,f16,f17,f18,f124"
+	// K线字段: 时间,开盘,收盘,最高,最低,成交量(手),成交额
+	eastmoneyKLineFields = "f51,f52,f53,f54,f55,f56,f57"
+)
+
+// eastmoneyQuoteResponse 行情API响应结构
+type eastmoneyQuoteResponse struct {
+	RC   int `json:"rc"`
+	Data *struct {
+		Diff []map[string]interface{} `json:"diff"`
+	} `json:"data"`
+}
+
+// eastmoneyKLineResponse K线API响应结构
+type eastmoneyKLineResponse struct {
+	RC   int `json:"rc"`
+	Data *struct {
+		Code   string   `json:"code"`
+		Market int      `json:"market"`
+		Name   string   `json:"name"`
+		KLines []string `json:"klines"`
+	} `json:"data"`
+}
+
+// EastmoneyDataSource 东方财富数据源
+type EastmoneyDataSource struct {
+	client     *http.Client
+	maxRetries int
+	adjust     model.AdjustType
+	quoteURL   string
+	klineURL   string
+	listURL    string
+	chunkSize  int
+	workers    int
+	limiter    *rateLimiter
+}
+
+// NewEastmoneyDataSource 创建东方财富数据源，adjust 为K线复权方式
+func NewEastmoneyDataSource(adjust model.AdjustType) *EastmoneyDataSource {
+	if adjust == "" {
+		adjust = model.AdjustNone
+	}
+	return &EastmoneyDataSource{
+		client:     &http.Client{Timeout: 10 * time.Second},
+		maxRetries: 3,
+		adjust:     adjust,
+		quoteURL:   eastmoneyQuoteURL,
+		klineURL:   eastmoneyKLineURL,
+		listURL:    eastmoneyListURL,
+		chunkSize:  defaultChunkSize,
+		workers:    defaultQuoteWorkers,
+		limiter:    newRateLimiter(defaultRatePerSec),
+	}
+}
+
+func (e *EastmoneyDataSource) Name() string {
+	return "eastmoney"
+}
+
+// secID 转换为东方财富 secid，如 1.600519 / 0.000001 / 116.00700
+//
+// 美股的市场编号因交易所而异（105/106/107），暂不支持。
+func (e *EastmoneyDataSource) secID(code string) (string, error) {
+	sec, err := security.Parse(code)
+	if err != nil {
+		return "", err
+	}
+	market := "0"
+	switch sec.Exchange {
+	case security.ExchangeSH:
+		market = "1"
+	case security.ExchangeHK:
+		market = "116"
+	case security.ExchangeUS:
+		return "", fmt.Errorf("东方财富数据源暂不支持美股: %s", code)
+	}
+	return market + "." + sec.Code, nil
+}
+
+// symbolFromMarket 由东方财富市场编号还原证券代码，1为上证，116为港股，0为深证/北证
+func symbolFromMarket(market int, code string) (security.Security, error) {
+	switch market {
+	case 1:
+		return security.Parse("sh" + code)
+	case 116:
+		return security.Parse("hk" + code)
+	}
+	return security.Parse(code)
+}
+
+func (e *EastmoneyDataSource) get(ctx context.Context, endpoint string, query url.Values) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Referer", "https://quote.eastmoney.com")
+
+	resp, err := doWithRetry(e.client, req, e.maxRetries, e.limiter)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("东方财富接口返回状态码: %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// GetRealTimeQuote 获取实时行情，代码较多时自动分批并发请求
+func (e *EastmoneyDataSource) GetRealTimeQuote(ctx context.Context, codes []string) ([]*model.Stock, error) {
+	return fetchChunked(ctx, codes, e.chunkSize, e.workers, e.fetchQuotes)
+}
+
+// fetchQuotes 单次请求一批代码的行情
+func (e *EastmoneyDataSource) fetchQuotes(ctx context.Context, codes []string) ([]*model.Stock, error) {
+	secids := make([]string, len(codes))
+	for i, code := range codes {
+		id, err := e.secID(code)
+		if err != nil {
+			return nil, err
+		}
+		secids[i] = id
+	}
+
+	query := url.Values{}
+	query.Set("fltt", "2")
+	query.Set("secids", strings.Join(secids, ","))
+	query.Set("fields", eastmoneyQuoteFields)
+
+	body, err := e.get(ctx, e.quoteURL, query)
+	if err != nil {
+		return nil, err
+	}
+	stocks, err := e.parseQuoteResponse(body)
+	if err != nil {
+		return nil, err
+	}
+	if failed := missingCodes(codes, stocks, QuoteErrors{}); len(failed) > 0 {
+		return stocks, failed
+	}
+	return stocks, nil
+}
+
+// parseQuoteResponse 解析行情响应，停牌等无效字段为 "-"，最新价为 "-" 时标记为停牌
+func (e *EastmoneyDataSource) parseQuoteResponse(body []byte) ([]*model.Stock, error) {
+	var resp eastmoneyQuoteResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data == nil {
+		return nil, nil
+	}
+
+	stocks := make([]*model.Stock, 0, len(resp.Data.Diff))
+	for _, item := range resp.Data.Diff {
+		code, _ := item["f12"].(string)
+		sec, err := symbolFromMarket(int(parseFloat(item["f13"])), code)
+		if err != nil {
+			continue
+		}
+		name, _ := item["f14"].(string)
+
+		stock := &model.Stock{
+			Code:     sec.Canonical(),
+			Exchange: string(sec.Exchange),
+			Name:     name,
+			Price:    parseFloat(item["f2"]),
+			High:     parseFloat(item["f15"]),
+			Low:      parseFloat(item["f16"]),
+			Open:     parseFloat(item["f17"]),
+			PreClose: parseFloat(item["f18"]),
+			Volume:   volumeShares(parseFloat(item["f5"]), sec),
+			Amount:   parseFloat(item["f6"]),
+		}
+		stock.Close = stock.Price
+		if price, ok := item["f2"].(string); ok && price == "-" {
+			stock.Status = model.QuoteSuspended
+		}
+		if ts := int64(parseFloat(item["f124"])); ts > 0 {
+			stock.Time = time.Unix(ts, 0).In(time.Local)
+		}
+		stocks = append(stocks, stock)
+	}
+	return stocks, nil
+}
+
+// ListSecurities 分页获取沪深京A股列表
+func (e *EastmoneyDataSource) ListSecurities(ctx context.Context) ([]security.Entry, error) {
+	var entries []security.Entry
+	err := e.listPages(ctx, eastmoneyListFilter, "f12,f13,f14", func(item map[string]interface{}) {
+		code, _ := item["f12"].(string)
+		name, _ := item["f14"].(string)
+		sec, err := symbolFromMarket(int(parseFloat(item["f13"])), code)
+		if err != nil || name == "" {
+			return
+		}
+		entries = append(entries, security.Entry{Security: sec, Name: name})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// listPages 分页请求列表接口，逐条回调
+func (e *EastmoneyDataSource) listPages(ctx context.Context, filter, fields string, fn func(item map[string]interface{})) error {
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("pn", strconv.Itoa(page))
+		query.Set("pz", strconv.Itoa(eastmoneyListPage))
+		query.Set("np", "1")
+		query.Set("fltt", "2")
+		query.Set("fs", filter)
+		query.Set("fields", fields)
+
+		body, err := e.get(ctx, e.listURL, query)
+		if err != nil {
+			return err
+		}
+		var resp struct {
+			Data *struct {
+				Total int                      `json:"total"`
+				Diff  []map[string]interface{} `json:"diff"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return err
+		}
+		if resp.Data == nil || len(resp.Data.Diff) == 0 {
+			return nil
+		}
+		for _, item := range resp.Data.Diff {
+			fn(item)
+		}
+		if page*eastmoneyListPage >= resp.Data.Total {
+			return nil
+		}
+	}
+}
+
+// GetKLine 获取K线数据
+func (e *EastmoneyDataSource) GetKLine(ctx context.Context, code string, ktype model.KLineType, count int) (*model.KLineData, error) {
+	secid, err := e.secID(code)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("secid", secid)
+	query.Set("fields1", "f1,f2,f3")
+	query.Set("fields2", eastmoneyKLineFields)
+	query.Set("klt", e.klineTypeToKLT(ktype))
+	query.Set("fqt", e.adjustToFQT())
+	query.Set("end", "20500101")
+	query.Set("lmt", strconv.Itoa(count))
+
+	body, err := e.get(ctx, e.klineURL, query)
+	if err != nil {
+		return nil, err
+	}
+	data, err := e.parseKLineResponse(body, code, ktype)
+	if err != nil {
+		return nil, err
+	}
+	markForming(data, time.Now())
+	return data, nil
+}
+
+func (e *EastmoneyDataSource) klineTypeToKLT(ktype model.KLineType) string {
+	switch ktype {
+	case model.KLine5Min:
+		return "5"
+	case model.KLine15Min:
+		return "15"
+	case model.KLine30Min:
+		return "30"
+	case model.KLine60Min:
+		return "60"
+	case model.KLineWeekly:
+		return "102"
+	case model.KLineMonthly:
+		return "103"
+	default:
+		return "101"
+	}
+}
+
+func (e *EastmoneyDataSource) adjustToFQT() string {
+	switch e.adjust {
+	case model.AdjustQFQ:
+		return "1"
+	case model.AdjustHFQ:
+		return "2"
+	default:
+		return "0"
+	}
+}
+
+// parseKLineResponse 解析K线响应，每根K线为逗号分隔的字符串
+func (e *EastmoneyDataSource) parseKLineResponse(body []byte, code string, ktype model.KLineType) (*model.KLineData, error) {
+	var resp eastmoneyKLineResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data == nil {
+		return nil, fmt.Errorf("invalid kline response: %s", code)
+	}
+
+	sec, _ := security.Parse(code)
+	market := sec.Market()
+	klineData := &model.KLineData{
+		Code:   code,
+		Type:   ktype,
+		Adjust: e.adjust,
+		Lines:  make([]model.KLine, 0, len(resp.Data.KLines)),
+	}
+
+	for _, row := range resp.Data.KLines {
+		fields := strings.Split(row, ",")
+		if len(fields) < 6 {
+			return nil, fmt.Errorf("invalid kline row: %s", row)
+		}
+		t, err := parseBarTimeIn(fields[0], market.Location)
+		if err != nil {
+			return nil, err
+		}
+		kline := model.KLine{Time: t}
+		kline.Open, _ = strconv.ParseFloat(fields[1], 64)
+		kline.Close, _ = strconv.ParseFloat(fields[2], 64)
+		kline.High, _ = strconv.ParseFloat(fields[3], 64)
+		kline.Low, _ = strconv.ParseFloat(fields[4], 64)
+		vol, _ := strconv.ParseFloat(fields[5], 64)
+		kline.Volume = volumeShares(vol, sec)
+		klineData.Lines = append(klineData.Lines, kline)
+	}
+
+	if err := normalizeKLines(klineData); err != nil {
+		return nil, err
+	}
+	return klineData, nil
+}
+
+// parseBarTime 解析K线时间，支持日期与分钟级时间
+func parseBarTime(s string) (time.Time, error) {
+	return parseBarTimeIn(s, time.Local)
+}
+
+// parseBarTimeIn 按指定时区解析K线时间
+func parseBarTimeIn(s string, loc *time.Location) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid kline time: %s", s)
+}
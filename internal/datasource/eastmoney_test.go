@@ -0,0 +1,134 @@
+package datasource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"stock-monitor/internal/model"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("read fixture %s: %v", name, err)
+	}
+	return data
+}
+
+func TestEastmoneyParseQuote(t *testing.T) {
+	e := NewEastmoneyDataSource(model.AdjustNone)
+	stocks, err := e.parseQuoteResponse(readFixture(t, "eastmoney_quote.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stocks) != 3 {
+		t.Fatalf("got %d stocks, want 3", len(stocks))
+	}
+
+	tests := []struct {
+		code     string
+		exchange string
+		name     string
+		price    float64
+		preClose float64
+		volume   int64
+	}{
+		{"600519", "sh", "贵州茅台", 1688.0, 1685.32, 2453100},
+		{"000001", "sz", "平安银行", 9.39, 9.21, 115896400},
+		{"sh000001", "sh", "上证指数", 0, 2974.93, 0},
+	}
+	for i, tt := range tests {
+		s := stocks[i]
+		if s.Code != tt.code || s.Exchange != tt.exchange || s.Name != tt.name {
+			t.Errorf("stock %d: got %s/%s/%s, want %s/%s/%s", i, s.Code, s.Exchange, s.Name, tt.code, tt.exchange, tt.name)
+		}
+		if s.Price != tt.price || s.Close != tt.price || s.PreClose != tt.preClose || s.Volume != tt.volume {
+			t.Errorf("stock %s: got price=%v pre=%v vol=%d", tt.code, s.Price, s.PreClose, s.Volume)
+		}
+		if s.Time.Unix() != 1704180600 {
+			t.Errorf("stock %s: got time %v", tt.code, s.Time)
+		}
+	}
+}
+
+func TestEastmoneyParseKLine(t *testing.T) {
+	e := NewEastmoneyDataSource(model.AdjustQFQ)
+
+	daily, err := e.parseKLineResponse(readFixture(t, "eastmoney_kline_daily.json"), "600519", model.KLineDaily)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if daily.Adjust != model.AdjustQFQ || len(daily.Lines) != 4 {
+		t.Fatalf("got adjust=%s lines=%d", daily.Adjust, len(daily.Lines))
+	}
+	last := daily.Lines[3]
+	want := model.KLine{
+		Time:   time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local),
+		Open:   1715.00,
+		High:   1718.19,
+		Low:    1678.10,
+		Close:  1685.01,
+		Volume: 3215600,
+	}
+	if last != want {
+		t.Errorf("got %+v, want %+v", last, want)
+	}
+
+	minute, err := e.parseKLineResponse(readFixture(t, "eastmoney_kline_5min.json"), "000001", model.KLine5Min)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantTime := time.Date(2024, 1, 2, 9, 40, 0, 0, time.Local)
+	if got := minute.Lines[1].Time; !got.Equal(wantTime) {
+		t.Errorf("got bar time %v, want %v", got, wantTime)
+	}
+}
+
+func TestEastmoneyOverseasVolume(t *testing.T) {
+	e := NewEastmoneyDataSource(model.AdjustNone)
+
+	// 港股成交量单位为股，不按手换算
+	stocks, err := e.parseQuoteResponse(readFixture(t, "eastmoney_quote_hk.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stocks) != 1 || stocks[0].Code != "hk00700" || stocks[0].Volume != 18234567 {
+		t.Fatalf("got %+v, want hk00700 volume 18234567", stocks[0])
+	}
+
+	data, err := e.parseKLineResponse(readFixture(t, "eastmoney_kline_hk.json"), "hk00700", model.KLineDaily)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := data.Lines[len(data.Lines)-1]
+	if last.Volume != 18234567 || last.Close != 295.4 {
+		t.Errorf("got %+v, want close 295.4 volume 18234567", last)
+	}
+}
+
+func TestEastmoneyGetKLineRequest(t *testing.T) {
+	fixture := readFixture(t, "eastmoney_kline_daily.json")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("secid") != "1.600519" || q.Get("klt") != "101" || q.Get("fqt") != "2" || q.Get("lmt") != "4" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		w.Write(fixture)
+	}))
+	defer srv.Close()
+
+	e := NewEastmoneyDataSource(model.AdjustHFQ)
+	e.klineURL = srv.URL
+	data, err := e.GetKLine(context.Background(), "600519", model.KLineDaily, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Lines) != 4 {
+		t.Errorf("got %d lines, want 4", len(data.Lines))
+	}
+}
@@ -0,0 +1,160 @@
+package datasource
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+)
+
+// FundamentalsProvider 基本面数据来源（可选）
+type FundamentalsProvider interface {
+	// GetFundamentals 批量获取基本面数据，以规范代码为键，缺失的代码不出现在结果中
+	GetFundamentals(ctx context.Context, codes []string) (map[string]*model.Fundamentals, error)
+}
+
+// TencentFundamentalsProvider 由腾讯实时行情中的市盈率、市净率、市值和换手率字段提供基本面数据
+type TencentFundamentalsProvider struct {
+	quotes DataSource
+}
+
+// NewTencentFundamentalsProvider 创建腾讯基本面数据来源
+func NewTencentFundamentalsProvider() *TencentFundamentalsProvider {
+	return &TencentFundamentalsProvider{quotes: NewTencentDataSource(model.AdjustNone)}
+}
+
+// GetFundamentals 获取基本面数据，部分代码失败时返回其余代码的数据及 QuoteErrors
+func (p *TencentFundamentalsProvider) GetFundamentals(ctx context.Context, codes []string) (map[string]*model.Fundamentals, error) {
+	stocks, err := p.quotes.GetRealTimeQuote(ctx, codes)
+	if len(stocks) == 0 {
+		return nil, err
+	}
+	result := make(map[string]*model.Fundamentals, len(stocks))
+	for _, st := range stocks {
+		if f := model.FundamentalsOf(st); f != nil {
+			result[st.Code] = f
+		}
+	}
+	return result, err
+}
+
+// FixtureFundamentalsProvider 从本地CSV加载基本面数据，用于离线运行和测试
+//
+// 首行为表头，列名为 code,pe,pb,market_cap,float_market_cap,turnover_rate，顺序不限，
+// 市值单位为亿元；文件变化时自动重新加载。
+type FixtureFundamentalsProvider struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	data    map[string]*model.Fundamentals
+}
+
+// NewFixtureFundamentalsProvider 创建本地基本面数据来源
+func NewFixtureFundamentalsProvider(path string) *FixtureFundamentalsProvider {
+	return &FixtureFundamentalsProvider{path: path}
+}
+
+// GetFundamentals 获取基本面数据
+func (p *FixtureFundamentalsProvider) GetFundamentals(ctx context.Context, codes []string) (map[string]*model.Fundamentals, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return nil, err
+	}
+	if p.data == nil || !info.ModTime().Equal(p.modTime) {
+		if err := p.load(info.ModTime()); err != nil {
+			return nil, fmt.Errorf("解析基本面文件 %s 失败: %w", p.path, err)
+		}
+		p.modTime = info.ModTime()
+	}
+
+	result := make(map[string]*model.Fundamentals, len(codes))
+	for _, code := range codes {
+		sec, err := security.Parse(code)
+		if err != nil {
+			continue
+		}
+		if f, ok := p.data[sec.Canonical()]; ok {
+			copied := *f
+			result[sec.Canonical()] = &copied
+		}
+	}
+	return result, nil
+}
+
+func (p *FixtureFundamentalsProvider) load(modTime time.Time) error {
+	f, err := os.Open(p.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := cols["code"]; !ok {
+		return fmt.Errorf("缺少 code 列")
+	}
+
+	data := make(map[string]*model.Fundamentals)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		sec, err := security.Parse(record[cols["code"]])
+		if err != nil {
+			return fmt.Errorf("第%d行: %w", line, err)
+		}
+		num := func(name string) float64 {
+			i, ok := cols[name]
+			if !ok || i >= len(record) {
+				return 0
+			}
+			v, _ := strconv.ParseFloat(strings.TrimSpace(record[i]), 64)
+			return v
+		}
+		data[sec.Canonical()] = &model.Fundamentals{
+			Code:           sec.Canonical(),
+			PE:             num("pe"),
+			PB:             num("pb"),
+			MarketCap:      num("market_cap") * 1e8,
+			FloatMarketCap: num("float_market_cap") * 1e8,
+			TurnoverRate:   num("turnover_rate"),
+			Time:           modTime,
+		}
+	}
+	p.data = data
+	return nil
+}
+
+// NewFundamentalsProvider 按配置创建基本面数据来源：tencent 使用腾讯行情，其余视为本地CSV路径，未配置时返回 nil
+func NewFundamentalsProvider(cfg Config) FundamentalsProvider {
+	switch cfg.Fundamentals {
+	case "":
+		return nil
+	case "tencent":
+		return NewTencentFundamentalsProvider()
+	}
+	return NewFixtureFundamentalsProvider(cfg.Fundamentals)
+}
@@ -0,0 +1,71 @@
+package datasource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"stock-monitor/internal/model"
+)
+
+func TestFixtureFundamentalsProvider(t *testing.T) {
+	p := NewFixtureFundamentalsProvider(filepath.Join("testdata", "fundamentals.csv"))
+	got, err := p.GetFundamentals(context.Background(), []string{"600519", "sz000001", "300750"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d entries, want 2", len(got))
+	}
+
+	mt := got["600519"]
+	if mt == nil || mt.PE != 25.08 || mt.PB != 9.17 || mt.MarketCap != 21204.97e8 || mt.TurnoverRate != 0.20 {
+		t.Errorf("600519 = %+v", mt)
+	}
+	pa := got["000001"]
+	if pa == nil || pa.FloatMarketCap != 2150.1e8 || pa.TurnoverRate != 12.4 {
+		t.Errorf("000001 = %+v", pa)
+	}
+}
+
+func TestFixtureFundamentalsProviderMissingFile(t *testing.T) {
+	p := NewFixtureFundamentalsProvider(filepath.Join("testdata", "missing.csv"))
+	if _, err := p.GetFundamentals(context.Background(), []string{"600519"}); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+func TestTencentFundamentalsProvider(t *testing.T) {
+	fixture := readFixture(t, "tencent_quote.txt")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(fixture)
+	}))
+	defer srv.Close()
+
+	tc := NewTencentDataSource(model.AdjustNone)
+	tc.quoteURL = srv.URL + "/?q="
+	p := &TencentFundamentalsProvider{quotes: tc}
+
+	got, err := p.GetFundamentals(context.Background(), []string{"600519", "000001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := got["600519"]
+	if f == nil || f.PE != 25.08 || f.PB != 9.17 || f.TurnoverRate != 0.20 {
+		t.Fatalf("600519 = %+v", f)
+	}
+}
+
+func TestNewFundamentalsProvider(t *testing.T) {
+	if p := NewFundamentalsProvider(Config{}); p != nil {
+		t.Errorf("empty config = %T, want nil", p)
+	}
+	if _, ok := NewFundamentalsProvider(Config{Fundamentals: "tencent"}).(*TencentFundamentalsProvider); !ok {
+		t.Error("tencent config should create TencentFundamentalsProvider")
+	}
+	if _, ok := NewFundamentalsProvider(Config{Fundamentals: "data.csv"}).(*FixtureFundamentalsProvider); !ok {
+		t.Error("path config should create FixtureFundamentalsProvider")
+	}
+}
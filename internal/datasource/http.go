@@ -0,0 +1,31 @@
+package datasource
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// doWithRetry 带指数退避重试的HTTP请求，每次尝试（含重试）前都经过限流
+func doWithRetry(client *http.Client, req *http.Request, maxRetries int, limiter *rateLimiter) (*http.Response, error) {
+	var lastErr error
+	for i := 0; i < maxRetries; i++ {
+		if i > 0 {
+			select {
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			case <-time.After(time.Duration(i) * time.Second):
+			}
+		}
+		if err := limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return resp, nil
+	}
+	return nil, fmt.Errorf("请求失败(重试%d次): %w", maxRetries, lastErr)
+}
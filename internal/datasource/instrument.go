@@ -0,0 +1,330 @@
+package datasource
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+// BondTerms 可转债条款
+type BondTerms struct {
+	Code            string  // 转债代码
+	UnderlyingCode  string  // 正股代码
+	ConversionPrice float64 // 当前转股价
+}
+
+// BondTermsSource 可转债条款来源
+type BondTermsSource interface {
+	// GetBondTerms 获取转债条款，没有该转债时返回 nil
+	GetBondTerms(ctx context.Context, code string) (*BondTerms, error)
+}
+
+// CSVBondTermsSource 从本地CSV加载可转债条款
+//
+// 每行 "转债代码,正股代码,转股价"，首行表头可选；转股价下修后更新文件即可，文件变化时自动重新加载。
+// 文件不存在时视为没有条款，只记录一次日志。
+type CSVBondTermsSource struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	terms   map[string]*BondTerms // 规范代码 -> 条款
+	missing bool                  // 已记录文件不存在
+}
+
+// NewCSVBondTermsSource 创建CSV可转债条款来源
+func NewCSVBondTermsSource(path string) *CSVBondTermsSource {
+	return &CSVBondTermsSource{path: path}
+}
+
+// GetBondTerms 获取转债条款
+func (c *CSVBondTermsSource) GetBondTerms(ctx context.Context, code string) (*BondTerms, error) {
+	sec, err := security.Parse(code)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info, err := os.Stat(c.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		if !c.missing {
+			c.missing = true
+			slog.Warn("可转债条款文件不存在，不计算转股溢价率", "path", c.path)
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.missing = false
+	if c.terms == nil || !info.ModTime().Equal(c.modTime) {
+		if err := c.load(); err != nil {
+			return nil, fmt.Errorf("解析可转债条款 %s 失败: %w", c.path, err)
+		}
+		c.modTime = info.ModTime()
+	}
+	return c.terms[sec.Canonical()], nil
+}
+
+func (c *CSVBondTermsSource) load() error {
+	f, err := os.Open(c.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	terms := make(map[string]*BondTerms)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if len(record) < 3 {
+			continue
+		}
+		bond, err := security.Parse(record[0])
+		if err != nil {
+			if line == 1 {
+				continue // 表头
+			}
+			return fmt.Errorf("第%d行: %w", line, err)
+		}
+		underlying, err := security.Parse(record[1])
+		if err != nil {
+			return fmt.Errorf("第%d行: %w", line, err)
+		}
+		price, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil || price <= 0 {
+			return fmt.Errorf("第%d行转股价无效: %s", line, record[2])
+		}
+		terms[bond.Canonical()] = &BondTerms{
+			Code:            bond.Canonical(),
+			UnderlyingCode:  underlying.Canonical(),
+			ConversionPrice: price,
+		}
+	}
+	c.terms = terms
+	return nil
+}
+
+// IOPVSource ETF参考净值来源
+type IOPVSource interface {
+	// GetIOPV 批量获取参考净值，缺失的代码不出现在结果中
+	GetIOPV(ctx context.Context, codes []string) (map[string]float64, error)
+}
+
+const sinaIOPVURL = "https://hq.sinajs.cn/list="
+
+var sinaIOPVRegexp = regexp.MustCompile(`var hq_str_fu_(\d{6})="([^"]*)"`)
+
+// SinaIOPVSource 新浪基金估值接口提供的盘中估算净值
+//
+// 响应格式为 fu_510300="名称,时间,估算净值,昨日单位净值,累计净值,五分钟涨速,估算涨跌幅,日期"。
+type SinaIOPVSource struct {
+	client  *http.Client
+	url     string
+	limiter *rateLimiter
+}
+
+// NewSinaIOPVSource 创建新浪ETF估算净值来源
+func NewSinaIOPVSource() *SinaIOPVSource {
+	return &SinaIOPVSource{
+		client:  &http.Client{Timeout: 10 * time.Second},
+		url:     sinaIOPVURL,
+		limiter: newRateLimiter(defaultRatePerSec),
+	}
+}
+
+// GetIOPV 批量获取估算净值
+func (s *SinaIOPVSource) GetIOPV(ctx context.Context, codes []string) (map[string]float64, error) {
+	if len(codes) == 0 {
+		return nil, nil
+	}
+	symbols := make([]string, 0, len(codes))
+	for _, code := range codes {
+		sec, err := security.Parse(code)
+		if err != nil {
+			return nil, err
+		}
+		symbols = append(symbols, "fu_"+sec.Code)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", s.url+strings.Join(symbols, ","), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Referer", "https://finance.sina.com.cn")
+	resp, err := doWithRetry(s.client, req, 3, s.limiter)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(transform.NewReader(resp.Body, simplifiedchinese.GBK.NewDecoder()))
+	if err != nil {
+		return nil, err
+	}
+	return parseSinaIOPV(string(body)), nil
+}
+
+// parseSinaIOPV 解析估算净值，结果以6位代码为键
+func parseSinaIOPV(body string) map[string]float64 {
+	result := make(map[string]float64)
+	for _, match := range sinaIOPVRegexp.FindAllStringSubmatch(body, -1) {
+		fields := strings.Split(match[2], ",")
+		if len(fields) < 3 {
+			continue
+		}
+		if v, err := strconv.ParseFloat(fields[2], 64); err == nil && v > 0 {
+			result[match[1]] = v
+		}
+	}
+	return result
+}
+
+// InstrumentDataSource 品种数据源：为行情补充品种类型、可转债溢价率和ETF溢价率
+//
+// 可转债按条款查找正股，正股不在本次请求中时额外请求一次正股行情；
+// 补充数据失败只记录日志，不影响行情本身。
+type InstrumentDataSource struct {
+	DataSource
+	bonds BondTermsSource
+	iopv  IOPVSource
+}
+
+// NewInstrumentDataSource 创建品种数据源，bonds、iopv 可为 nil
+func NewInstrumentDataSource(ds DataSource, bonds BondTermsSource, iopv IOPVSource) *InstrumentDataSource {
+	return &InstrumentDataSource{DataSource: ds, bonds: bonds, iopv: iopv}
+}
+
+// GetRealTimeQuote 获取实时行情并补充品种数据
+func (d *InstrumentDataSource) GetRealTimeQuote(ctx context.Context, codes []string) ([]*model.Stock, error) {
+	stocks, err := d.DataSource.GetRealTimeQuote(ctx, codes)
+	for _, st := range stocks {
+		if sec, perr := security.Parse(st.Code); perr == nil && st.Type == "" {
+			st.Type = instrumentType(sec)
+		}
+	}
+	if len(stocks) > 0 {
+		if d.bonds != nil {
+			d.fillBonds(ctx, stocks)
+		}
+		if d.iopv != nil {
+			d.fillETFs(ctx, stocks)
+		}
+	}
+	return stocks, err
+}
+
+func (d *InstrumentDataSource) fillBonds(ctx context.Context, stocks []*model.Stock) {
+	prices := make(map[string]*model.Stock, len(stocks))
+	for _, st := range stocks {
+		prices[st.Code] = st
+	}
+
+	terms := make(map[*model.Stock]*BondTerms)
+	var missing []string
+	for _, st := range stocks {
+		if st.Type != model.InstrumentBond {
+			continue
+		}
+		t, err := d.bonds.GetBondTerms(ctx, st.Code)
+		if err != nil {
+			slog.Warn("获取可转债条款失败", "code", st.Code, "error", err)
+			return
+		}
+		if t == nil {
+			continue
+		}
+		terms[st] = t
+		if _, ok := prices[t.UnderlyingCode]; !ok {
+			missing = append(missing, t.UnderlyingCode)
+			prices[t.UnderlyingCode] = nil
+		}
+	}
+
+	if len(missing) > 0 {
+		underlying, err := d.DataSource.GetRealTimeQuote(ctx, missing)
+		if err != nil && len(underlying) == 0 {
+			slog.Warn("获取正股行情失败", "codes", missing, "error", err)
+		}
+		for _, st := range underlying {
+			prices[st.Code] = st
+		}
+	}
+
+	for st, t := range terms {
+		u := prices[t.UnderlyingCode]
+		if u == nil || u.Price <= 0 {
+			continue
+		}
+		st.Bond = model.NewBondInfo(st.Price, u.Price, t.ConversionPrice)
+		st.Bond.UnderlyingCode = t.UnderlyingCode
+		st.Bond.UnderlyingName = u.Name
+	}
+}
+
+func (d *InstrumentDataSource) fillETFs(ctx context.Context, stocks []*model.Stock) {
+	var codes []string
+	for _, st := range stocks {
+		if st.Type == model.InstrumentETF {
+			codes = append(codes, st.Code)
+		}
+	}
+	if len(codes) == 0 {
+		return
+	}
+
+	iopv, err := d.iopv.GetIOPV(ctx, codes)
+	if err != nil {
+		slog.Warn("获取ETF参考净值失败", "error", err)
+		return
+	}
+	for _, st := range stocks {
+		sec, err := security.Parse(st.Code)
+		if err != nil || st.Type != model.InstrumentETF {
+			continue
+		}
+		if v, ok := iopv[sec.Code]; ok {
+			st.ETF = model.NewETFInfo(st.Price, v)
+		}
+	}
+}
+
+// ListSecurities 透传内部数据源的证券列表
+func (d *InstrumentDataSource) ListSecurities(ctx context.Context) ([]security.Entry, error) {
+	return listSecurities(ctx, d.DataSource)
+}
+
+// instrumentType 证券品种，无法识别时为空
+func instrumentType(sec security.Security) model.InstrumentType {
+	if sec.Type == security.TypeUnknown {
+		return ""
+	}
+	return model.InstrumentType(sec.Type)
+}
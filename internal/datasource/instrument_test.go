@@ -0,0 +1,92 @@
+package datasource
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"stock-monitor/internal/model"
+)
+
+// quoteMapDataSource 按代码返回固定价格的数据源
+type quoteMapDataSource struct {
+	stubDataSource
+	prices map[string]float64
+	calls  int
+}
+
+func (q *quoteMapDataSource) GetRealTimeQuote(ctx context.Context, codes []string) ([]*model.Stock, error) {
+	q.calls++
+	var stocks []*model.Stock
+	for _, code := range codes {
+		if p, ok := q.prices[code]; ok {
+			stocks = append(stocks, &model.Stock{Code: code, Name: code, Price: p})
+		}
+	}
+	return stocks, nil
+}
+
+type staticIOPV map[string]float64
+
+func (s staticIOPV) GetIOPV(ctx context.Context, codes []string) (map[string]float64, error) {
+	return s, nil
+}
+
+func TestInstrumentDataSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bond_terms.csv")
+	terms := "转债代码,正股代码,转股价\n113050,601012,25.00\n"
+	if err := os.WriteFile(path, []byte(terms), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	inner := &quoteMapDataSource{prices: map[string]float64{
+		"113050": 110,
+		"601012": 25,
+		"510300": 3.03,
+	}}
+	ds := NewInstrumentDataSource(inner, NewCSVBondTermsSource(path), staticIOPV{"510300": 3.0})
+
+	stocks, err := ds.GetRealTimeQuote(context.Background(), []string{"113050", "510300"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inner.calls != 2 {
+		t.Errorf("inner called %d times, want 2 (quotes + underlying)", inner.calls)
+	}
+
+	bond := stocks[0].Bond
+	if bond == nil {
+		t.Fatal("bond info missing")
+	}
+	if bond.UnderlyingCode != "601012" || bond.ConversionValue != 100 || math.Abs(bond.Premium-10) > 1e-9 {
+		t.Errorf("bond = %+v", bond)
+	}
+	etf := stocks[1].ETF
+	if etf == nil || math.Abs(etf.Premium-1) > 1e-9 {
+		t.Errorf("etf = %+v", etf)
+	}
+	if stocks[0].Type != model.InstrumentBond || stocks[1].Type != model.InstrumentETF {
+		t.Errorf("types = %s, %s, want bond, etf", stocks[0].Type, stocks[1].Type)
+	}
+}
+
+func TestParseSinaIOPV(t *testing.T) {
+	body := `var hq_str_fu_510300="沪深300ETF,14:58:00,3.9821,3.9650,1.6820,0.05,0.4312,2024-01-03";
+var hq_str_fu_159915="";`
+	got := parseSinaIOPV(body)
+	if len(got) != 1 || got["510300"] != 3.9821 {
+		t.Errorf("parseSinaIOPV = %v", got)
+	}
+}
+
+func TestCSVBondTermsMissingFile(t *testing.T) {
+	src := NewCSVBondTermsSource(filepath.Join(t.TempDir(), "bond_terms.csv"))
+	for i := 0; i < 2; i++ {
+		terms, err := src.GetBondTerms(context.Background(), "113050")
+		if terms != nil || err != nil {
+			t.Fatalf("got %+v, %v; want nil, nil", terms, err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package datasource
+
+import (
+	"fmt"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+)
+
+// normalizeKLines 校验K线时间：缺少时间或顺序倒退时报错，重复时间只保留最后一根
+func normalizeKLines(data *model.KLineData) error {
+	lines := data.Lines[:0]
+	for i, line := range data.Lines {
+		if line.Time.IsZero() {
+			return fmt.Errorf("kline %s: bar %d has no time", data.Code, i)
+		}
+		if n := len(lines); n > 0 {
+			prev := lines[n-1].Time
+			if line.Time.Equal(prev) {
+				lines[n-1] = line
+				continue
+			}
+			if line.Time.Before(prev) {
+				return fmt.Errorf("kline %s: bar %s out of order after %s",
+					data.Code, line.Time.Format(time.DateTime), prev.Format(time.DateTime))
+			}
+		}
+		lines = append(lines, line)
+	}
+	data.Lines = lines
+	return nil
+}
+
+// markForming 按当前时间标记最后一根K线是否仍在形成，收盘时间按证券所属市场
+func markForming(data *model.KLineData, now time.Time) {
+	data.Forming = false
+	if n := len(data.Lines); n > 0 {
+		data.Forming = isFormingBar(data.Lines[n-1].Time, data.Type, now, security.MarketOf(data.Code))
+	}
+}
+
+// isFormingBar 判断K线是否尚未完成
+//
+// 分钟K线时间为结束时间，当前时间早于结束时间即未完成；
+// 日K在当日收盘前、周K/月K在本周/本月最后一天收盘前未完成。
+// 日K及以上的K线时间只取年月日，按市场当地日期比较。
+func isFormingBar(barTime time.Time, ktype model.KLineType, now time.Time, market *security.Market) bool {
+	if ktype.Minutes() > 0 {
+		return now.Before(barTime)
+	}
+
+	now = market.In(now)
+	closeTime := market.CloseTime(now)
+	by, bm, bd := barTime.Date()
+	ny, nm, nd := now.Date()
+
+	switch ktype {
+	case model.KLineWeekly:
+		bYear, bWeek := barTime.ISOWeek()
+		nYear, nWeek := now.ISOWeek()
+		if bYear != nYear || bWeek != nWeek {
+			return false
+		}
+		switch now.Weekday() {
+		case time.Saturday, time.Sunday:
+			return false
+		case time.Friday:
+			return now.Before(closeTime)
+		}
+		return true
+	case model.KLineMonthly:
+		if by != ny || bm != nm {
+			return false
+		}
+		lastDay := time.Date(ny, nm+1, 0, 0, 0, 0, 0, now.Location()).Day()
+		return nd != lastDay || now.Before(closeTime)
+	default:
+		return by == ny && bm == nm && bd == nd && now.Before(closeTime)
+	}
+}
+
+// volumeShares 成交量换算为股：沪深京行情以手（100股）为单位，港股美股已是股
+func volumeShares(vol float64, sec security.Security) int64 {
+	if sec.Overseas() {
+		return int64(vol)
+	}
+	return int64(vol) * 100
+}
@@ -0,0 +1,344 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// LocalDataSource 本地文件数据源，读取通达信/同花顺导出的K线文件
+//
+// 目录结构为 <dir>/<周期>/<代码文件>，周期目录名同 KLineType（daily、5min 等），
+// 代码文件支持 600519、sh600519、SH#600519、600519.SH 等命名，扩展名为 .csv 或 .txt。
+// 实时行情由日K最后一根合成，昨收取前一根收盘价。
+type LocalDataSource struct {
+	dir string
+	now func() time.Time
+
+	mu    sync.Mutex
+	files map[string]*localFile // 文件路径 -> 解析结果
+}
+
+// localFile 已解析的K线文件
+type localFile struct {
+	modTime time.Time
+	name    string // 通达信标题行中的证券名称
+	adjust  model.AdjustType
+	lines   []model.KLine
+}
+
+// NewLocalDataSource 创建本地文件数据源
+func NewLocalDataSource(dir string) *LocalDataSource {
+	return &LocalDataSource{
+		dir:   dir,
+		now:   time.Now,
+		files: make(map[string]*localFile),
+	}
+}
+
+func (l *LocalDataSource) Name() string {
+	return "local"
+}
+
+// GetRealTimeQuote 由日K最后一根合成行情，没有日K文件的代码记为 unknown
+func (l *LocalDataSource) GetRealTimeQuote(ctx context.Context, codes []string) ([]*model.Stock, error) {
+	var stocks []*model.Stock
+	failed := QuoteErrors{}
+	for _, code := range codes {
+		sec, err := security.Parse(code)
+		if err != nil {
+			failed[code] = &CodeError{Code: code, Status: model.QuoteMalformed, Reason: err.Error()}
+			continue
+		}
+		f, err := l.open(sec, model.KLineDaily)
+		if err != nil {
+			failed[code] = err
+			continue
+		}
+		if f == nil || len(f.lines) == 0 {
+			failed[code] = &CodeError{Code: code, Status: model.QuoteUnknown, Reason: "无本地日K文件"}
+			continue
+		}
+
+		last := f.lines[len(f.lines)-1]
+		stock := &model.Stock{
+			Code:     sec.Canonical(),
+			Name:     f.name,
+			Exchange: string(sec.Exchange),
+			Price:    last.Close,
+			Open:     last.Open,
+			High:     last.High,
+			Low:      last.Low,
+			Close:    last.Close,
+			Volume:   last.Volume,
+			Time:     sec.Market().CloseTime(last.Time),
+		}
+		if n := len(f.lines); n > 1 {
+			stock.PreClose = f.lines[n-2].Close
+		}
+		stocks = append(stocks, stock)
+	}
+
+	if len(failed) > 0 {
+		if len(stocks) == 0 {
+			return nil, failed
+		}
+		return stocks, failed
+	}
+	return stocks, nil
+}
+
+// GetKLine 读取本地K线文件的最后 count 根
+func (l *LocalDataSource) GetKLine(ctx context.Context, code string, ktype model.KLineType, count int) (*model.KLineData, error) {
+	sec, err := security.Parse(code)
+	if err != nil {
+		return nil, err
+	}
+	f, err := l.open(sec, ktype)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return nil, fmt.Errorf("no local %s kline file for %s", ktype, code)
+	}
+
+	lines := f.lines
+	if count > 0 && len(lines) > count {
+		lines = lines[len(lines)-count:]
+	}
+	data := &model.KLineData{
+		Code:   code,
+		Type:   ktype,
+		Adjust: f.adjust,
+		Lines:  append([]model.KLine(nil), lines...),
+	}
+	markForming(data, l.now())
+	return data, nil
+}
+
+// open 查找并解析K线文件，文件未变化时使用缓存；找不到文件时返回 nil
+func (l *LocalDataSource) open(sec security.Security, ktype model.KLineType) (*localFile, error) {
+	path, info := l.find(sec, ktype)
+	if path == "" {
+		return nil, nil
+	}
+
+	l.mu.Lock()
+	cached, ok := l.files[path]
+	l.mu.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) {
+		return cached, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parseLocalKLine(raw, ktype, sec.Market().Location)
+	if err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %w", path, err)
+	}
+	f.modTime = info.ModTime()
+
+	l.mu.Lock()
+	l.files[path] = f
+	l.mu.Unlock()
+	return f, nil
+}
+
+// find 按常见命名查找K线文件
+func (l *LocalDataSource) find(sec security.Security, ktype model.KLineType) (string, os.FileInfo) {
+	ex := string(sec.Exchange)
+	upper := strings.ToUpper(ex)
+	names := []string{
+		sec.Code,
+		sec.Symbol(),
+		upper + "#" + sec.Code,
+		sec.Code + "." + upper,
+		upper + sec.Code,
+	}
+	for _, name := range names {
+		for _, ext := range []string{".csv", ".txt"} {
+			path := filepath.Join(l.dir, string(ktype), name+ext)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path, info
+			}
+		}
+	}
+	return "", nil
+}
+
+// localColumns K线文件各列下标，-1 表示不存在
+type localColumns struct {
+	date, clock, open, high, low, close, volume int
+}
+
+// 无表头时按通达信默认列顺序：日期[,时间],开盘,最高,最低,收盘,成交量,成交额
+var (
+	tdxDailyColumns  = localColumns{date: 0, clock: -1, open: 1, high: 2, low: 3, close: 4, volume: 5}
+	tdxMinuteColumns = localColumns{date: 0, clock: 1, open: 2, high: 3, low: 4, close: 5, volume: 6}
+)
+
+// parseLocalKLine 解析K线文件，支持 GBK/UTF-8 编码，逗号、制表符或空格分隔
+//
+// 通达信导出首行为 "600519 贵州茅台 日线 前复权"，末行为 "数据来源:通达信"；
+// 同花顺导出首行为表头。无法解析日期的行跳过，时间按市场当地时区 loc 解析。
+func parseLocalKLine(raw []byte, ktype model.KLineType, loc *time.Location) (*localFile, error) {
+	if !utf8.Valid(raw) {
+		decoded, err := simplifiedchinese.GBK.NewDecoder().Bytes(raw)
+		if err != nil {
+			return nil, err
+		}
+		raw = decoded
+	}
+
+	f := &localFile{adjust: model.AdjustNone}
+	cols := tdxDailyColumns
+	if ktype.Minutes() > 0 {
+		cols = tdxMinuteColumns
+	}
+
+	rows := strings.Split(strings.ReplaceAll(string(raw), "\r\n", "\n"), "\n")
+	for i, row := range rows {
+		row = strings.TrimSpace(strings.TrimPrefix(row, "\ufeff"))
+		if row == "" {
+			continue
+		}
+		fields := splitLocalRow(row)
+
+		if i == 0 && strings.Contains(row, "线") {
+			if len(fields) > 1 {
+				f.name = fields[1]
+			}
+			switch {
+			case strings.Contains(row, "前复权"):
+				f.adjust = model.AdjustQFQ
+			case strings.Contains(row, "后复权"):
+				f.adjust = model.AdjustHFQ
+			}
+			continue
+		}
+		if header, ok := parseLocalHeader(fields); ok {
+			cols = header
+			continue
+		}
+
+		bar, ok := parseLocalRow(fields, cols, loc)
+		if !ok {
+			continue
+		}
+		f.lines = append(f.lines, bar)
+	}
+
+	data := &model.KLineData{Type: ktype, Lines: f.lines}
+	if err := normalizeKLines(data); err != nil {
+		return nil, err
+	}
+	f.lines = data.Lines
+	return f, nil
+}
+
+func splitLocalRow(row string) []string {
+	var fields []string
+	if strings.ContainsAny(row, ",\t") {
+		fields = strings.FieldsFunc(row, func(r rune) bool { return r == ',' || r == '\t' })
+	} else {
+		fields = strings.Fields(row)
+	}
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields
+}
+
+// parseLocalHeader 按表头列名确定列下标
+func parseLocalHeader(fields []string) (localColumns, bool) {
+	cols := localColumns{-1, -1, -1, -1, -1, -1, -1}
+	for i, name := range fields {
+		switch strings.ToLower(name) {
+		case "日期", "date", "交易日期":
+			cols.date = i
+		case "时间", "time":
+			if cols.date < 0 && i == 0 {
+				cols.date = i // 同花顺分钟线 "时间" 列为完整时间
+			} else {
+				cols.clock = i
+			}
+		case "开盘", "开盘价", "open":
+			cols.open = i
+		case "最高", "最高价", "high":
+			cols.high = i
+		case "最低", "最低价", "low":
+			cols.low = i
+		case "收盘", "收盘价", "close":
+			cols.close = i
+		case "成交量", "volume", "vol":
+			cols.volume = i
+		}
+	}
+	ok := cols.date >= 0 && cols.open >= 0 && cols.high >= 0 && cols.low >= 0 && cols.close >= 0
+	return cols, ok
+}
+
+func parseLocalRow(fields []string, cols localColumns, loc *time.Location) (model.KLine, bool) {
+	need := max(cols.date, cols.clock, cols.open, cols.high, cols.low, cols.close)
+	if len(fields) <= need {
+		return model.KLine{}, false
+	}
+
+	ts := fields[cols.date]
+	if cols.clock >= 0 {
+		ts += " " + fields[cols.clock]
+	}
+	barTime, err := parseLocalTime(ts, loc)
+	if err != nil {
+		return model.KLine{}, false
+	}
+
+	num := func(i int) float64 {
+		if i < 0 || i >= len(fields) {
+			return 0
+		}
+		v, _ := strconv.ParseFloat(fields[i], 64)
+		return v
+	}
+	return model.KLine{
+		Time:   barTime,
+		Open:   num(cols.open),
+		High:   num(cols.high),
+		Low:    num(cols.low),
+		Close:  num(cols.close),
+		Volume: int64(num(cols.volume)),
+	}, true
+}
+
+// parseLocalTime 解析导出文件中的日期时间，如 2024/01/02、20240102、2024-01-02 0935
+func parseLocalTime(s string, loc *time.Location) (time.Time, error) {
+	date, clock, _ := strings.Cut(strings.TrimSpace(s), " ")
+	date = strings.NewReplacer("/", "-", ".", "-").Replace(date)
+	if len(date) == 8 && !strings.Contains(date, "-") {
+		date = date[:4] + "-" + date[4:6] + "-" + date[6:]
+	}
+	clock = strings.ReplaceAll(strings.TrimSpace(clock), ":", "")
+	if clock == "" {
+		return time.ParseInLocation("2006-1-2", date, loc)
+	}
+	if len(clock) == 3 {
+		clock = "0" + clock
+	}
+	if len(clock) == 4 {
+		clock += "00"
+	}
+	return time.ParseInLocation("2006-1-2 150405", date+" "+clock, loc)
+}
@@ -0,0 +1,59 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stock-monitor/internal/model"
+)
+
+func TestLocalDataSourceKLine(t *testing.T) {
+	l := NewLocalDataSource("testdata/local")
+	ctx := context.Background()
+
+	daily, err := l.GetKLine(ctx, "600519", model.KLineDaily, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if daily.Adjust != model.AdjustQFQ || len(daily.Lines) != 2 {
+		t.Fatalf("got adjust=%s lines=%d, want qfq 2", daily.Adjust, len(daily.Lines))
+	}
+	last := daily.Lines[1]
+	if last.Time.Format("2006-01-02") != "2024-01-04" || last.Close != 1669 || last.Volume != 2155107 {
+		t.Errorf("got last bar %+v", last)
+	}
+
+	minute, err := l.GetKLine(ctx, "000001", model.KLine5Min, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(minute.Lines) != 3 || minute.Lines[2].Time.Format("15:04") != "15:00" || minute.Lines[2].Close != 9.13 {
+		t.Errorf("got 5min bars %+v", minute.Lines)
+	}
+
+	if _, err := l.GetKLine(ctx, "000001", model.KLineDaily, 10); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestLocalDataSourceQuote(t *testing.T) {
+	l := NewLocalDataSource("testdata/local")
+	stocks, err := l.GetRealTimeQuote(context.Background(), []string{"600519", "000001"})
+	if len(stocks) != 1 {
+		t.Fatalf("got %d stocks, want 1", len(stocks))
+	}
+	s := stocks[0]
+	if s.Name != "贵州茅台" || s.Price != 1669 || s.PreClose != 1694 || s.Exchange != "sh" {
+		t.Errorf("got quote %+v", s)
+	}
+	if want := time.Date(2024, 1, 4, 15, 0, 0, 0, time.Local); !s.Time.Equal(want) {
+		t.Errorf("got time %v, want %v", s.Time, want)
+	}
+
+	var failed QuoteErrors
+	if !errors.As(err, &failed) || failed["000001"] == nil {
+		t.Errorf("got err %v, want 000001 failed", err)
+	}
+}
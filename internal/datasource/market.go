@@ -0,0 +1,145 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+)
+
+// MajorIndices 市场概况跟踪的主要指数：上证指数、深证成指、创业板指、科创50
+var MajorIndices = []string{"sh000001", "sz399001", "sz399006", "sh000688"}
+
+// MarketSnapshotter 可提供沪深全市场行情快照的数据源
+type MarketSnapshotter interface {
+	MarketSnapshot(ctx context.Context) ([]*model.Stock, error)
+}
+
+const (
+	// 沪深A股范围（不含北交所）: 深市主板,创业板,沪市主板,科创板
+	eastmoneySnapshotFilter = "m:0+t:6,m:0+t:80,m:1+t:2,m:1+t:23"
+	// 快照字段: 代码,市场,名称,最新价,成交量(手),成交额,昨收
+	eastmoneySnapshotFields = "f12,f13,f14,f2,f5,f6,f18"
+)
+
+// MarketSnapshot 分页获取沪深A股行情快照，停牌股票最新价为 "-"，标记为停牌
+func (e *EastmoneyDataSource) MarketSnapshot(ctx context.Context) ([]*model.Stock, error) {
+	var stocks []*model.Stock
+	err := e.listPages(ctx, eastmoneySnapshotFilter, eastmoneySnapshotFields, func(item map[string]interface{}) {
+		code, _ := item["f12"].(string)
+		sec, err := symbolFromMarket(int(parseFloat(item["f13"])), code)
+		if err != nil {
+			return
+		}
+		name, _ := item["f14"].(string)
+		stock := &model.Stock{
+			Code:     sec.Canonical(),
+			Exchange: string(sec.Exchange),
+			Name:     name,
+			Type:     instrumentType(sec),
+			Price:    parseFloat(item["f2"]),
+			PreClose: parseFloat(item["f18"]),
+			Volume:   int64(parseFloat(item["f5"])) * 100,
+			Amount:   parseFloat(item["f6"]),
+		}
+		stock.Close = stock.Price
+		if price, ok := item["f2"].(string); ok && price == "-" {
+			stock.Status = model.QuoteSuspended
+		}
+		stocks = append(stocks, stock)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stocks, nil
+}
+
+// ComputeBreadth 由全市场快照统计涨跌家数、涨跌停家数和成交额
+//
+// 涨跌停价优先使用行情提供的价格，否则按昨收和板块涨跌幅计算；N/C 开头的新股不计涨跌停。
+func ComputeBreadth(stocks []*model.Stock) *model.MarketOverview {
+	m := &model.MarketOverview{}
+	for _, st := range stocks {
+		if st.Status == model.QuoteSuspended || st.Price <= 0 || st.PreClose <= 0 {
+			m.Suspended++
+			continue
+		}
+		m.Amount += st.Amount
+		switch {
+		case st.Price > st.PreClose:
+			m.Advancing++
+		case st.Price < st.PreClose:
+			m.Declining++
+		default:
+			m.Unchanged++
+		}
+
+		sec, err := security.Parse(st.Code)
+		if err != nil {
+			continue
+		}
+		up, down := st.LimitUp, st.LimitDown
+		if up <= 0 || down <= 0 {
+			ratio := sec.LimitRatio(st.Name)
+			if ratio == 0 || isNewListing(st.Name) {
+				continue
+			}
+			up, down = security.LimitPrices(st.PreClose, ratio, sec.Tick())
+		}
+		half := sec.Tick() / 2
+		switch {
+		case st.Price >= up-half:
+			m.LimitUp++
+		case st.Price <= down+half:
+			m.LimitDown++
+		}
+	}
+	return m
+}
+
+// MarketCollector 市场概况采集：全市场快照统计涨跌家数，并获取主要指数行情
+type MarketCollector struct {
+	snap    MarketSnapshotter
+	ds      DataSource
+	indices []string
+	now     func() time.Time
+}
+
+// NewMarketCollector 创建市场概况采集，snap 为空时只获取指数行情
+func NewMarketCollector(snap MarketSnapshotter, ds DataSource) *MarketCollector {
+	return &MarketCollector{snap: snap, ds: ds, indices: MajorIndices, now: time.Now}
+}
+
+// Collect 采集一次市场概况，指数行情获取失败时只记录日志
+func (c *MarketCollector) Collect(ctx context.Context) (*model.MarketOverview, error) {
+	overview := &model.MarketOverview{}
+	if c.snap != nil {
+		stocks, err := c.snap.MarketSnapshot(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("获取全市场快照失败: %w", err)
+		}
+		overview = ComputeBreadth(stocks)
+	}
+	overview.Time = c.now()
+
+	indices, err := c.ds.GetRealTimeQuote(ctx, c.indices)
+	if err != nil {
+		if len(indices) == 0 && c.snap == nil {
+			return nil, err
+		}
+		slog.Warn("获取指数行情失败", "error", err)
+	}
+	overview.Indices = indices
+	return overview, nil
+}
+
+// NewMarketCollectorFromConfig 按配置创建市场概况采集，全市场快照来自东方财富
+func NewMarketCollectorFromConfig(cfg Config, ds DataSource) *MarketCollector {
+	if len(cfg.Replay) > 0 || cfg.Name == "local" {
+		return NewMarketCollector(nil, ds)
+	}
+	return NewMarketCollector(NewEastmoneyDataSource(cfg.Adjust), ds)
+}
@@ -0,0 +1,47 @@
+package datasource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"stock-monitor/internal/model"
+)
+
+func TestMarketCollector(t *testing.T) {
+	fixture := readFixture(t, "eastmoney_snapshot.json")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fs := r.URL.Query().Get("fs"); fs != eastmoneySnapshotFilter {
+			t.Errorf("unexpected fs: %s", fs)
+		}
+		w.Write(fixture)
+	}))
+	defer srv.Close()
+
+	e := NewEastmoneyDataSource(model.AdjustNone)
+	e.listURL = srv.URL
+	indices := &quoteMapDataSource{prices: map[string]float64{"sh000001": 2900}}
+
+	overview, err := NewMarketCollector(e, indices).Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 平安银行涨停(9.21*1.1=10.13)，*ST国华主板ST跌停(4.26*0.9=3.83)，宁德时代创业板跌停(30*0.8=24)
+	want := model.MarketOverview{Advancing: 2, Declining: 2, Unchanged: 1, Suspended: 1, LimitUp: 1, LimitDown: 2}
+	if overview.Advancing != want.Advancing || overview.Declining != want.Declining ||
+		overview.Unchanged != want.Unchanged || overview.Suspended != want.Suspended ||
+		overview.LimitUp != want.LimitUp || overview.LimitDown != want.LimitDown {
+		t.Errorf("overview = %+v", overview)
+	}
+	if amount := 4137412352.0 + 1091288320 + 121500000 + 1200000000 + 12500000; overview.Amount != amount {
+		t.Errorf("amount = %.0f, want %.0f", overview.Amount, amount)
+	}
+	if overview.Index("sh000001") == nil || len(overview.Indices) != 1 {
+		t.Errorf("indices = %v", overview.Indices)
+	}
+	if overview.Time.IsZero() {
+		t.Error("time not set")
+	}
+}
@@ -0,0 +1,33 @@
+package datasource
+
+import (
+	"context"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+)
+
+// OverseasKLineDataSource 港股美股K线路由：内部数据源不提供港股美股K线（如新浪）时，
+// 改由 overseas 获取，A股K线与实时行情不受影响
+type OverseasKLineDataSource struct {
+	DataSource
+	overseas DataSource
+}
+
+// NewOverseasKLineDataSource 创建港股美股K线路由
+func NewOverseasKLineDataSource(ds, overseas DataSource) *OverseasKLineDataSource {
+	return &OverseasKLineDataSource{DataSource: ds, overseas: overseas}
+}
+
+// GetKLine 获取K线数据，港股美股转交 overseas
+func (o *OverseasKLineDataSource) GetKLine(ctx context.Context, code string, ktype model.KLineType, count int) (*model.KLineData, error) {
+	if sec, err := security.Parse(code); err == nil && sec.Overseas() {
+		return o.overseas.GetKLine(ctx, code, ktype, count)
+	}
+	return o.DataSource.GetKLine(ctx, code, ktype, count)
+}
+
+// ListSecurities 透传内部数据源的证券列表
+func (o *OverseasKLineDataSource) ListSecurities(ctx context.Context) ([]security.Entry, error) {
+	return listSecurities(ctx, o.DataSource)
+}
@@ -0,0 +1,64 @@
+package datasource
+
+import (
+	"context"
+	"testing"
+
+	"stock-monitor/internal/model"
+)
+
+func TestOverseasKLineRouting(t *testing.T) {
+	cn := &fakeSource{name: "cn"}
+	overseas := &fakeSource{name: "overseas"}
+	ds := NewOverseasKLineDataSource(cn, overseas)
+	ctx := context.Background()
+
+	tests := []struct {
+		code     string
+		wantCN   int
+		wantOver int
+	}{
+		{"600519", 1, 0},
+		{"hk00700", 1, 1},
+		{"usAAPL", 1, 2},
+		{"sh000001", 2, 2},
+	}
+	for _, tt := range tests {
+		if _, err := ds.GetKLine(ctx, tt.code, model.KLineDaily, 10); err != nil {
+			t.Errorf("%s: %v", tt.code, err)
+		}
+		if cn.calls != tt.wantCN || overseas.calls != tt.wantOver {
+			t.Errorf("%s: calls cn=%d overseas=%d, want %d/%d", tt.code, cn.calls, overseas.calls, tt.wantCN, tt.wantOver)
+		}
+	}
+
+	// 实时行情不经过路由
+	cn.prices = map[string]float64{"hk00700": 320}
+	if stocks, err := ds.GetRealTimeQuote(ctx, []string{"hk00700"}); err != nil || len(stocks) != 1 {
+		t.Errorf("quote = %v, %v", stocks, err)
+	}
+}
+
+func TestNewSinaRoutesOverseasKLine(t *testing.T) {
+	ds, err := newSource("sina", Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, ok := ds.(*OverseasKLineDataSource)
+	if !ok {
+		t.Fatalf("sina source = %T, want *OverseasKLineDataSource", ds)
+	}
+	if _, ok := o.overseas.(*TencentDataSource); !ok {
+		t.Errorf("overseas K-line source = %T, want *TencentDataSource", o.overseas)
+	}
+
+	// 新浪本身不支持港股K线，路由后不再返回该错误
+	sina := NewSinaDataSource()
+	if _, err := sina.GetKLine(context.Background(), "hk00700", model.KLineDaily, 1); err == nil {
+		t.Fatal("expected sina to reject hk K-lines")
+	}
+	o.overseas = &fakeSource{name: "tencent"}
+	if _, err := o.GetKLine(context.Background(), "hk00700", model.KLineDaily, 1); err != nil {
+		t.Errorf("routed hk K-line: %v", err)
+	}
+}
@@ -0,0 +1,360 @@
+package datasource
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+)
+
+// 录制文件中的请求类型
+const (
+	recordQuote = "quote"
+	recordKLine = "kline"
+)
+
+// ErrEmptyRecord 录制的K线响应既没有数据也没有错误信息
+var ErrEmptyRecord = errors.New("录制的响应为空")
+
+// record 录制文件中的一条记录，文件为每行一条的 JSON
+type record struct {
+	Time   time.Time                  `json:"time"`
+	Method string                     `json:"method"`
+	Codes  []string                   `json:"codes,omitempty"`
+	Stocks []*model.Stock             `json:"stocks,omitempty"`
+	Failed map[string]recordCodeError `json:"failed,omitempty"`
+	Code   string                     `json:"code,omitempty"`
+	KType  model.KLineType            `json:"ktype,omitempty"`
+	Count  int                        `json:"count,omitempty"`
+	KLine  *model.KLineData           `json:"kline,omitempty"`
+	Error  string                     `json:"error,omitempty"`
+}
+
+// recordCodeError 单个代码的失败原因
+type recordCodeError struct {
+	Status model.QuoteStatus `json:"status"`
+	Reason string            `json:"reason,omitempty"`
+}
+
+// RecordingDataSource 录制数据源：透传内部数据源，并把每次行情与K线响应追加写入文件
+type RecordingDataSource struct {
+	DataSource
+	now func() time.Time
+
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+// NewRecordingDataSource 创建录制数据源，path 已存在时追加写入
+func NewRecordingDataSource(ds DataSource, path string) (*RecordingDataSource, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &RecordingDataSource{
+		DataSource: ds,
+		now:        time.Now,
+		f:          f,
+		enc:        json.NewEncoder(f),
+	}, nil
+}
+
+// Close 关闭录制文件
+func (r *RecordingDataSource) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
+
+func (r *RecordingDataSource) write(rec *record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.enc.Encode(rec); err != nil {
+		slog.Warn("写入录制文件失败", "file", r.f.Name(), "error", err)
+	}
+}
+
+// GetRealTimeQuote 获取并录制实时行情
+func (r *RecordingDataSource) GetRealTimeQuote(ctx context.Context, codes []string) ([]*model.Stock, error) {
+	stocks, err := r.DataSource.GetRealTimeQuote(ctx, codes)
+
+	rec := &record{Time: r.now(), Method: recordQuote, Codes: codes, Stocks: stocks}
+	if err != nil {
+		rec.Error = err.Error()
+		got := make(map[string]bool, len(stocks))
+		for _, st := range stocks {
+			got[st.Code] = true
+		}
+		rec.Failed = make(map[string]recordCodeError)
+		for code, status := range QuoteStatuses(codes, stocks, err) {
+			if sec, perr := security.Parse(code); perr == nil && got[sec.Canonical()] {
+				continue
+			}
+			if status != model.QuoteOK {
+				rec.Failed[code] = recordCodeError{Status: status, Reason: codeReason(err, code)}
+			}
+		}
+	}
+	r.write(rec)
+	return stocks, err
+}
+
+// codeReason 取出单个代码的失败原因
+func codeReason(err error, code string) string {
+	var failed QuoteErrors
+	if errors.As(err, &failed) {
+		var codeErr *CodeError
+		if errors.As(failed[code], &codeErr) {
+			return codeErr.Reason
+		}
+		if failed[code] != nil {
+			return failed[code].Error()
+		}
+		return ""
+	}
+	return err.Error()
+}
+
+// GetKLine 获取并录制K线数据
+func (r *RecordingDataSource) GetKLine(ctx context.Context, code string, ktype model.KLineType, count int) (*model.KLineData, error) {
+	data, err := r.DataSource.GetKLine(ctx, code, ktype, count)
+
+	rec := &record{Time: r.now(), Method: recordKLine, Code: code, KType: ktype, Count: count, KLine: data}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	r.write(rec)
+	return data, err
+}
+
+// ListSecurities 透传内部数据源的证券列表
+func (r *RecordingDataSource) ListSecurities(ctx context.Context) ([]security.Entry, error) {
+	return listSecurities(ctx, r.DataSource)
+}
+
+// VirtualClock 回放用的虚拟时钟
+type VirtualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewVirtualClock 创建虚拟时钟
+func NewVirtualClock(start time.Time) *VirtualClock {
+	return &VirtualClock{now: start}
+}
+
+// Now 当前虚拟时间
+func (c *VirtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set 设置虚拟时间
+func (c *VirtualClock) Set(t time.Time) {
+	c.mu.Lock()
+	c.now = t
+	c.mu.Unlock()
+}
+
+// Advance 推进虚拟时间
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// quoteEvent 某一时刻单个代码的行情或失败原因
+type quoteEvent struct {
+	time  time.Time
+	stock *model.Stock
+	err   *CodeError
+}
+
+// klineEvent 某一时刻的K线响应
+type klineEvent struct {
+	time time.Time
+	data *model.KLineData
+	err  string
+}
+
+// ReplayDataSource 回放数据源：按虚拟时钟返回录制文件中不晚于当前时间的最近一次响应
+type ReplayDataSource struct {
+	clock  *VirtualClock
+	quotes map[string][]quoteEvent // 规范代码 -> 按时间升序
+	klines map[string][]klineEvent // 代码_周期 -> 按时间升序
+	times  []time.Time             // 行情记录时间，升序去重
+}
+
+// NewReplayDataSource 加载录制文件创建回放数据源，clock 为空时从第一条记录的时间开始
+func NewReplayDataSource(clock *VirtualClock, paths ...string) (*ReplayDataSource, error) {
+	r := &ReplayDataSource{
+		quotes: make(map[string][]quoteEvent),
+		klines: make(map[string][]klineEvent),
+	}
+	for _, path := range paths {
+		if err := r.load(path); err != nil {
+			return nil, fmt.Errorf("加载录制文件 %s 失败: %w", path, err)
+		}
+	}
+
+	for _, events := range r.quotes {
+		sort.SliceStable(events, func(i, j int) bool { return events[i].time.Before(events[j].time) })
+	}
+	for _, events := range r.klines {
+		sort.SliceStable(events, func(i, j int) bool { return events[i].time.Before(events[j].time) })
+	}
+	sort.Slice(r.times, func(i, j int) bool { return r.times[i].Before(r.times[j]) })
+	r.times = dedupTimes(r.times)
+
+	if clock == nil {
+		var start time.Time
+		if len(r.times) > 0 {
+			start = r.times[0]
+		}
+		clock = NewVirtualClock(start)
+	}
+	r.clock = clock
+	return r, nil
+}
+
+func (r *ReplayDataSource) load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return fmt.Errorf("第%d行: %w", line, err)
+		}
+		switch rec.Method {
+		case recordQuote:
+			r.addQuote(&rec)
+		case recordKLine:
+			key := rec.Code + "_" + string(rec.KType)
+			r.klines[key] = append(r.klines[key], klineEvent{time: rec.Time, data: rec.KLine, err: rec.Error})
+		}
+	}
+	return scanner.Err()
+}
+
+func (r *ReplayDataSource) addQuote(rec *record) {
+	r.times = append(r.times, rec.Time)
+	for _, st := range rec.Stocks {
+		r.quotes[st.Code] = append(r.quotes[st.Code], quoteEvent{time: rec.Time, stock: st})
+	}
+	for code, f := range rec.Failed {
+		key := code
+		if sec, err := security.Parse(code); err == nil {
+			key = sec.Canonical()
+		}
+		r.quotes[key] = append(r.quotes[key], quoteEvent{
+			time: rec.Time,
+			err:  &CodeError{Code: code, Status: f.Status, Reason: f.Reason},
+		})
+	}
+}
+
+func dedupTimes(times []time.Time) []time.Time {
+	result := times[:0]
+	for _, t := range times {
+		if n := len(result); n == 0 || !result[n-1].Equal(t) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+func (r *ReplayDataSource) Name() string {
+	return "replay"
+}
+
+// Clock 返回回放使用的虚拟时钟
+func (r *ReplayDataSource) Clock() *VirtualClock {
+	return r.clock
+}
+
+// Next 将虚拟时钟推进到下一次行情记录的时间，没有更多记录时返回 false
+func (r *ReplayDataSource) Next() bool {
+	now := r.clock.Now()
+	i := sort.Search(len(r.times), func(i int) bool { return r.times[i].After(now) })
+	if i == len(r.times) {
+		return false
+	}
+	r.clock.Set(r.times[i])
+	return true
+}
+
+// GetRealTimeQuote 返回每个代码不晚于虚拟时间的最近一次行情
+func (r *ReplayDataSource) GetRealTimeQuote(ctx context.Context, codes []string) ([]*model.Stock, error) {
+	now := r.clock.Now()
+	var stocks []*model.Stock
+	failed := QuoteErrors{}
+	for _, code := range codes {
+		key := code
+		if sec, err := security.Parse(code); err == nil {
+			key = sec.Canonical()
+		}
+		events := r.quotes[key]
+		i := sort.Search(len(events), func(i int) bool { return events[i].time.After(now) }) - 1
+		switch {
+		case i < 0:
+			failed[code] = &CodeError{Code: code, Status: model.QuoteUnknown, Reason: "无录制数据"}
+		case events[i].err != nil:
+			failed[code] = events[i].err
+		default:
+			st := *events[i].stock
+			stocks = append(stocks, &st)
+		}
+	}
+	if len(failed) > 0 {
+		if len(stocks) == 0 {
+			return nil, fmt.Errorf("%s 时刻无录制行情: %w", now.Format(time.DateTime), failed)
+		}
+		return stocks, failed
+	}
+	return stocks, nil
+}
+
+// GetKLine 返回不晚于虚拟时间的最近一次K线响应
+func (r *ReplayDataSource) GetKLine(ctx context.Context, code string, ktype model.KLineType, count int) (*model.KLineData, error) {
+	now := r.clock.Now()
+	events := r.klines[code+"_"+string(ktype)]
+	i := sort.Search(len(events), func(i int) bool { return events[i].time.After(now) }) - 1
+	if i < 0 {
+		return nil, fmt.Errorf("%s %s 时刻无录制K线: %s", code, ktype, now.Format(time.DateTime))
+	}
+	if events[i].err != "" {
+		return nil, errors.New(events[i].err)
+	}
+	if events[i].data == nil {
+		return nil, fmt.Errorf("%s %s: %w", code, ktype, ErrEmptyRecord)
+	}
+
+	data := *events[i].data
+	lines := data.Lines
+	if count > 0 && len(lines) > count {
+		lines = lines[len(lines)-count:]
+	}
+	data.Lines = append([]model.KLine(nil), lines...)
+	markForming(&data, now)
+	return &data, nil
+}
@@ -0,0 +1,153 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"stock-monitor/internal/model"
+)
+
+// stubDataSource 返回固定行情与K线的数据源
+type stubDataSource struct {
+	price float64
+}
+
+func (s *stubDataSource) Name() string { return "stub" }
+
+func (s *stubDataSource) GetRealTimeQuote(ctx context.Context, codes []string) ([]*model.Stock, error) {
+	stocks := []*model.Stock{{Code: "600519", Name: "贵州茅台", Price: s.price}}
+	return stocks, QuoteErrors{"000002": &CodeError{Code: "000002", Status: model.QuoteSuspended, Reason: "停牌"}}
+}
+
+func (s *stubDataSource) GetKLine(ctx context.Context, code string, ktype model.KLineType, count int) (*model.KLineData, error) {
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local)
+	return &model.KLineData{Code: code, Type: ktype, Lines: []model.KLine{
+		{Time: day, Close: 1},
+		{Time: day.AddDate(0, 0, 1), Close: s.price},
+	}}, nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "record.jsonl")
+	stub := &stubDataSource{price: 1680}
+	rec, err := NewRecordingDataSource(stub, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 1, 3, 10, 0, 0, 0, time.Local)
+	ctx := context.Background()
+	codes := []string{"600519", "000002"}
+
+	rec.now = func() time.Time { return start }
+	rec.GetRealTimeQuote(ctx, codes)
+	rec.GetKLine(ctx, "600519", model.KLineDaily, 2)
+	stub.price = 1700
+	rec.now = func() time.Time { return start.Add(time.Minute) }
+	rec.GetRealTimeQuote(ctx, codes)
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err := NewReplayDataSource(nil, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !replay.Clock().Now().Equal(start) {
+		t.Fatalf("clock starts at %v, want %v", replay.Clock().Now(), start)
+	}
+
+	stocks, err := replay.GetRealTimeQuote(ctx, codes)
+	if len(stocks) != 1 || stocks[0].Price != 1680 {
+		t.Fatalf("got %+v, want price 1680", stocks)
+	}
+	var failed QuoteErrors
+	var codeErr *CodeError
+	if !errors.As(err, &failed) || !errors.As(failed["000002"], &codeErr) || codeErr.Status != model.QuoteSuspended {
+		t.Fatalf("got err %v, want 000002 suspended", err)
+	}
+
+	data, err := replay.GetKLine(ctx, "600519", model.KLineDaily, 1)
+	if err != nil || len(data.Lines) != 1 || data.Lines[0].Close != 1680 {
+		t.Fatalf("got kline %+v, err %v", data, err)
+	}
+	if !data.Forming {
+		t.Error("daily bar at 10:00 should be forming")
+	}
+
+	if !replay.Next() {
+		t.Fatal("expected second quote record")
+	}
+	stocks, _ = replay.GetRealTimeQuote(ctx, codes)
+	if len(stocks) != 1 || stocks[0].Price != 1700 {
+		t.Fatalf("got %+v after Next, want price 1700", stocks)
+	}
+	if replay.Next() {
+		t.Error("expected no more records")
+	}
+
+	replay.Clock().Set(start.Add(-time.Second))
+	if _, err := replay.GetKLine(ctx, "600519", model.KLineDaily, 1); err == nil {
+		t.Error("expected error before first record")
+	}
+}
+
+func TestNewRecordsBeforeValidation(t *testing.T) {
+	ds, err := New(Config{Name: "local", LocalDir: "testdata/local", Record: filepath.Join(t.TempDir(), "record.jsonl")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inst, ok := ds.(*InstrumentDataSource)
+	if !ok {
+		t.Fatalf("New = %T, want *InstrumentDataSource", ds)
+	}
+	v, ok := inst.DataSource.(*ValidatingDataSource)
+	if !ok {
+		t.Fatalf("instrument wraps %T, want *ValidatingDataSource", inst.DataSource)
+	}
+	if rec, ok := v.DataSource.(*RecordingDataSource); !ok {
+		t.Fatalf("validator wraps %T, want *RecordingDataSource", v.DataSource)
+	} else {
+		rec.Close()
+	}
+}
+
+func TestReplayValidatesRecordedQuotes(t *testing.T) {
+	// 零价行情被校验剔除，但原始响应已录制，回放时得到同样的结果
+	path := filepath.Join(t.TempDir(), "record.jsonl")
+	rec, err := NewRecordingDataSource(&stubDataSource{price: 0}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.now = func() time.Time { return time.Date(2024, 1, 3, 10, 0, 0, 0, time.Local) }
+	rec.GetRealTimeQuote(context.Background(), []string{"600519"})
+	rec.Close()
+
+	ds, err := New(Config{Replay: []string{path}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stocks, err := ds.GetRealTimeQuote(context.Background(), []string{"600519"})
+	if statuses := QuoteStatuses([]string{"600519"}, stocks, err); len(stocks) != 0 || statuses["600519"] != model.QuoteInvalid {
+		t.Errorf("replayed zero price = %+v, %v, want invalid", stocks, err)
+	}
+}
+
+func TestReplayEmptyKLineRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "record.jsonl")
+	line := `{"time":"2024-01-03T10:00:00+08:00","method":"kline","code":"600519","ktype":"daily","count":10}` + "\n"
+	if err := os.WriteFile(path, []byte(line), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	clock := NewVirtualClock(time.Date(2024, 1, 3, 10, 0, 0, 0, time.FixedZone("CST", 8*3600)))
+	replay, err := NewReplayDataSource(clock, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := replay.GetKLine(context.Background(), "600519", model.KLineDaily, 10); !errors.Is(err, ErrEmptyRecord) {
+		t.Errorf("err = %v, want ErrEmptyRecord", err)
+	}
+}
@@ -0,0 +1,68 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+)
+
+// fallbackSecurityLister 数据源不支持证券列表且没有离线列表时使用的来源
+var fallbackSecurityLister = func() SecurityLister { return NewEastmoneyDataSource(model.AdjustNone) }
+
+// RefreshSecurityList 刷新证券列表
+//
+// 数据源支持 SecurityLister 时拉取全市场快照并写回 csvPath 作为离线缓存；
+// 数据源不支持或拉取失败时从 csvPath 加载离线列表，离线列表不存在时改从东方财富拉取并生成。
+func RefreshSecurityList(ctx context.Context, ds DataSource, list *security.List, csvPath string) error {
+	if lister, ok := ds.(SecurityLister); ok {
+		err := fetchSecurityList(ctx, lister, list, csvPath)
+		if err == nil {
+			return nil
+		}
+		slog.Warn("获取证券列表失败，使用离线列表", "source", ds.Name(), "error", err)
+	}
+
+	if csvPath != "" {
+		err := list.LoadCSV(csvPath)
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		slog.Info("离线证券列表不存在，从东方财富获取", "path", csvPath)
+	}
+	if err := fetchSecurityList(ctx, fallbackSecurityLister(), list, csvPath); err != nil {
+		return fmt.Errorf("数据源 %s 不支持证券列表且无法获取离线列表: %w", ds.Name(), err)
+	}
+	return nil
+}
+
+// fetchSecurityList 拉取证券列表，成功时写回 csvPath
+func fetchSecurityList(ctx context.Context, lister SecurityLister, list *security.List, csvPath string) error {
+	entries, err := lister.ListSecurities(ctx)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("证券列表为空")
+	}
+	list.Replace(entries)
+	if csvPath != "" {
+		if err := list.SaveCSV(csvPath); err != nil {
+			slog.Warn("保存证券列表失败", "path", csvPath, "error", err)
+		}
+	}
+	return nil
+}
+
+// listSecurities 供装饰器透传内部数据源的证券列表
+func listSecurities(ctx context.Context, ds DataSource) ([]security.Entry, error) {
+	lister, ok := ds.(SecurityLister)
+	if !ok {
+		return nil, fmt.Errorf("数据源 %s 不支持证券列表", ds.Name())
+	}
+	return lister.ListSecurities(ctx)
+}
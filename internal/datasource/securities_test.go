@@ -0,0 +1,63 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"stock-monitor/internal/security"
+)
+
+type staticLister []security.Entry
+
+func (s staticLister) ListSecurities(ctx context.Context) ([]security.Entry, error) {
+	if s == nil {
+		return nil, errors.New("list unavailable")
+	}
+	return s, nil
+}
+
+func TestRefreshSecurityList(t *testing.T) {
+	sec, _ := security.Parse("600519")
+	entries := staticLister{{Security: sec, Name: "贵州茅台", Pinyin: "gzmt"}}
+
+	orig := fallbackSecurityLister
+	defer func() { fallbackSecurityLister = orig }()
+
+	tests := []struct {
+		name     string
+		seedCSV  string // 为空时离线列表不存在
+		fallback staticLister
+		wantErr  bool
+		wantName string
+	}{
+		{"离线列表存在", "code,name\nsh600519,茅台离线\n", entries, false, "茅台离线"},
+		{"离线列表缺失时拉取", "", entries, false, "贵州茅台"},
+		{"离线列表缺失且拉取失败", "", nil, true, ""},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "securities.csv")
+		if tt.seedCSV != "" {
+			os.WriteFile(path, []byte(tt.seedCSV), 0o644)
+		}
+		fallbackSecurityLister = func() SecurityLister { return tt.fallback }
+
+		list := security.NewList()
+		err := RefreshSecurityList(context.Background(), &stubDataSource{}, list, path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v", tt.name, err)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if e, ok := list.Lookup("600519"); !ok || e.Name != tt.wantName {
+			t.Errorf("%s: lookup = %+v, %v", tt.name, e, ok)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s: csv not written: %v", tt.name, err)
+		}
+	}
+}
@@ -12,12 +12,22 @@ import (
 	"time"
 
 	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
 
 	"golang.org/x/text/encoding/simplifiedchinese"
 	"golang.org/x/text/transform"
 )
 
-var quoteRegexp = regexp.MustCompile(`var hq_str_(\w+)="([^"]*)"`)
+var quoteRegexp = regexp.MustCompile(`var hq_str_([\w.$]+)="([^"]*)"`)
+
+// sinaLocation 新浪美股行情时间为北京时间
+var sinaLocation = func() *time.Location {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}()
 
 // klineAPIResponse K线API响应结构
 type klineAPIResponse struct {
@@ -31,6 +41,9 @@ type klineAPIResponse struct {
 type SinaDataSource struct {
 	client     *http.Client
 	maxRetries int
+	chunkSize  int
+	workers    int
+	limiter    *rateLimiter
 }
 
 // NewSinaDataSource 创建新浪数据源
@@ -38,6 +51,9 @@ func NewSinaDataSource() *SinaDataSource {
 	return &SinaDataSource{
 		client:     &http.Client{Timeout: 10 * time.Second},
 		maxRetries: 3,
+		chunkSize:  defaultChunkSize,
+		workers:    defaultQuoteWorkers,
+		limiter:    newRateLimiter(defaultRatePerSec),
 	}
 }
 
@@ -45,40 +61,44 @@ func (s *SinaDataSource) Name() string {
 	return "sina"
 }
 
-// doWithRetry 带指数退避重试的HTTP请求
-func (s *SinaDataSource) doWithRetry(req *http.Request) (*http.Response, error) {
-	var lastErr error
-	for i := 0; i < s.maxRetries; i++ {
-		if i > 0 {
-			select {
-			case <-req.Context().Done():
-				return nil, req.Context().Err()
-			case <-time.After(time.Duration(i) * time.Second):
-			}
-		}
-		resp, err := s.client.Do(req)
-		if err != nil {
-			lastErr = err
-			continue
-		}
-		return resp, nil
+// formatCode 格式化股票代码为新浪代码：A股 sh600519，港股 rt_hk00700，美股 gb_aapl
+func (s *SinaDataSource) formatCode(code string) (string, error) {
+	sec, err := security.Parse(code)
+	if err != nil {
+		return "", err
+	}
+	switch sec.Exchange {
+	case security.ExchangeHK:
+		return "rt_" + sec.Symbol(), nil
+	case security.ExchangeUS:
+		return "gb_" + strings.ToLower(sec.Code), nil
 	}
-	return nil, fmt.Errorf("请求失败(重试%d次): %w", s.maxRetries, lastErr)
+	return sec.Symbol(), nil
 }
 
-// formatCode 格式化股票代码
-func (s *SinaDataSource) formatCode(code string) string {
-	if strings.HasPrefix(code, "6") {
-		return "sh" + code
+// sinaSecurity 由新浪代码还原证券
+func sinaSecurity(symbol string) (security.Security, error) {
+	symbol = strings.TrimPrefix(symbol, "rt_")
+	if rest, ok := strings.CutPrefix(symbol, "gb_"); ok {
+		symbol = "us" + rest
 	}
-	return "sz" + code
+	return security.Parse(symbol)
 }
 
-// GetRealTimeQuote 获取实时行情
+// GetRealTimeQuote 获取实时行情，代码较多时自动分批并发请求
 func (s *SinaDataSource) GetRealTimeQuote(ctx context.Context, codes []string) ([]*model.Stock, error) {
+	return fetchChunked(ctx, codes, s.chunkSize, s.workers, s.fetchQuotes)
+}
+
+// fetchQuotes 单次请求一批代码的行情
+func (s *SinaDataSource) fetchQuotes(ctx context.Context, codes []string) ([]*model.Stock, error) {
 	symbols := make([]string, len(codes))
 	for i, code := range codes {
-		symbols[i] = s.formatCode(code)
+		symbol, err := s.formatCode(code)
+		if err != nil {
+			return nil, err
+		}
+		symbols[i] = symbol
 	}
 
 	url := fmt.Sprintf("https://hq.sinajs.cn/list=%s", strings.Join(symbols, ","))
@@ -88,7 +108,7 @@ func (s *SinaDataSource) GetRealTimeQuote(ctx context.Context, codes []string) (
 	}
 	req.Header.Set("Referer", "https://finance.sina.com.cn")
 
-	resp, err := s.doWithRetry(req)
+	resp, err := doWithRetry(s.client, req, s.maxRetries, s.limiter)
 	if err != nil {
 		return nil, err
 	}
@@ -104,26 +124,85 @@ func (s *SinaDataSource) GetRealTimeQuote(ctx context.Context, codes []string) (
 	return s.parseQuoteResponse(string(body), codes)
 }
 
+// sinaTradeStatus 新浪行情第33个字段（交易状态）对应的行情状态
+var sinaTradeStatus = map[string]model.QuoteStatus{
+	"00": model.QuoteOK,
+	"01": model.QuoteSuspended, // 停牌一小时
+	"02": model.QuoteSuspended, // 停牌一天
+	"03": model.QuoteSuspended, // 连续停牌
+	"04": model.QuoteSuspended, // 盘中停牌
+	"05": model.QuoteSuspended, // 停牌半天
+	"07": model.QuoteSuspended, // 暂停上市
+	"-1": model.QuoteUnknown,   // 无该记录
+	"-2": model.QuoteUnknown,   // 未上市
+	"-3": model.QuoteDelisted,  // 退市
+}
+
 // parseQuoteResponse 解析行情响应
+//
+// 停牌股票照常返回并标记 Status；空数据、字段不足、退市及响应中缺失的代码
+// 以 QuoteErrors 返回，其中每项为 *CodeError。
 func (s *SinaDataSource) parseQuoteResponse(body string, codes []string) ([]*model.Stock, error) {
 	matches := quoteRegexp.FindAllStringSubmatch(body, -1)
 
+	// 响应中的 symbol 对应请求时的原始代码
+	requested := make(map[string]string, len(codes))
+	for _, code := range codes {
+		if symbol, err := s.formatCode(code); err == nil {
+			requested[symbol] = code
+		}
+	}
+	failed := QuoteErrors{}
+
 	var stocks []*model.Stock
 	for _, match := range matches {
-		if len(match) < 3 || match[2] == "" {
+		symbol := match[1]
+		code, ok := requested[symbol]
+		if !ok {
+			code = symbol
+		}
+		if match[2] == "" {
+			failed[code] = &CodeError{Code: code, Status: model.QuoteUnknown}
 			continue
 		}
 
-		symbol := match[1]
 		data := strings.Split(match[2], ",")
+		sec, err := sinaSecurity(symbol)
+		if err != nil {
+			failed[code] = &CodeError{Code: code, Status: model.QuoteMalformed, Reason: err.Error()}
+			continue
+		}
+		if sec.Overseas() {
+			stock, err := parseSinaOverseas(sec, data)
+			if err != nil {
+				failed[code] = &CodeError{Code: code, Status: model.QuoteMalformed, Reason: err.Error()}
+				continue
+			}
+			stocks = append(stocks, stock)
+			continue
+		}
+
 		if len(data) < 32 {
+			failed[code] = &CodeError{Code: code, Status: model.QuoteMalformed, Reason: fmt.Sprintf("字段数 %d", len(data))}
+			continue
+		}
+
+		status := model.QuoteOK
+		if len(data) > 32 {
+			if st, ok := sinaTradeStatus[data[32]]; ok {
+				status = st
+			}
+		}
+		if status == model.QuoteUnknown || status == model.QuoteDelisted {
+			failed[code] = &CodeError{Code: code, Status: status, Reason: data[0]}
 			continue
 		}
 
 		stock := &model.Stock{
-			Code:     symbol[2:],
-			Exchange: symbol[:2],
+			Code:     sec.Canonical(),
+			Exchange: string(sec.Exchange),
 			Name:     data[0],
+			Status:   status,
 		}
 
 		stock.Open, _ = strconv.ParseFloat(data[1], 64)
@@ -136,18 +215,98 @@ func (s *SinaDataSource) parseQuoteResponse(body string, codes []string) ([]*mod
 		stock.Volume = int64(vol)
 		stock.Amount, _ = strconv.ParseFloat(data[9], 64)
 
+		stock.Depth = parseSinaDepth(data)
+
 		timeStr := data[30] + " " + data[31]
 		stock.Time, _ = time.ParseInLocation("2006-01-02 15:04:05", timeStr, time.Local)
 
 		stocks = append(stocks, stock)
 	}
 
+	if failed = missingCodes(codes, stocks, failed); len(failed) > 0 {
+		return stocks, failed
+	}
 	return stocks, nil
 }
 
+// parseSinaOverseas 解析港股美股行情
+//
+// 港股 rt_hk: 英文名,中文名,开盘,昨收,最高,最低,现价,涨跌,涨跌幅,买一,卖一,成交额,成交量,市盈率,...,日期,时间；
+// 美股 gb_: 名称,现价,涨跌幅,北京时间,涨跌额,开盘,最高,最低,52周最高,52周最低,成交量,均量,市值,每股收益,市盈率,...
+func parseSinaOverseas(sec security.Security, data []string) (*model.Stock, error) {
+	f := func(i int) float64 {
+		v, _ := strconv.ParseFloat(data[i], 64)
+		return v
+	}
+	stock := &model.Stock{
+		Code:     sec.Canonical(),
+		Exchange: string(sec.Exchange),
+		Status:   model.QuoteOK,
+	}
+
+	if sec.Exchange == security.ExchangeHK {
+		if len(data) < 19 {
+			return nil, fmt.Errorf("字段数 %d", len(data))
+		}
+		stock.Name = data[1]
+		stock.Open, stock.PreClose = f(2), f(3)
+		stock.High, stock.Low, stock.Price = f(4), f(5), f(6)
+		stock.Amount = f(11)
+		stock.Volume = int64(f(12))
+		stock.PE = f(13)
+		clock := data[18]
+		if len(clock) == 5 {
+			clock += ":00"
+		}
+		stock.Time, _ = time.ParseInLocation("2006/01/02 15:04:05", data[17]+" "+clock, security.MarketHK.Location)
+	} else {
+		if len(data) < 15 {
+			return nil, fmt.Errorf("字段数 %d", len(data))
+		}
+		stock.Name = data[0]
+		stock.Price = f(1)
+		stock.PreClose = stock.Price - f(4)
+		if len(data) > 26 && f(26) > 0 {
+			stock.PreClose = f(26)
+		}
+		stock.Open, stock.High, stock.Low = f(5), f(6), f(7)
+		stock.Volume = int64(f(10))
+		stock.MarketCap = f(12)
+		stock.PE = f(14)
+		stock.Time, _ = time.ParseInLocation("2006-01-02 15:04:05", data[3], sinaLocation)
+	}
+	stock.Close = stock.Price
+	return stock, nil
+}
+
+// parseSinaDepth 解析五档盘口：字段10-19为买一至买五的(量,价)，20-29为卖一至卖五
+func parseSinaDepth(data []string) *model.Depth {
+	depth := &model.Depth{}
+	for i := 0; i < model.DepthLevels; i++ {
+		depth.Bids[i] = parseSinaLevel(data[10+2*i], data[11+2*i])
+		depth.Asks[i] = parseSinaLevel(data[20+2*i], data[21+2*i])
+	}
+	return depth
+}
+
+func parseSinaLevel(volume, price string) model.DepthLevel {
+	level := model.DepthLevel{}
+	level.Price, _ = strconv.ParseFloat(price, 64)
+	vol, _ := strconv.ParseFloat(volume, 64)
+	level.Volume = int64(vol)
+	return level
+}
+
 // GetKLine 获取K线数据
 func (s *SinaDataSource) GetKLine(ctx context.Context, code string, ktype model.KLineType, count int) (*model.KLineData, error) {
-	symbol := s.formatCode(code)
+	sec, err := security.Parse(code)
+	if err != nil {
+		return nil, err
+	}
+	if sec.Overseas() {
+		return nil, fmt.Errorf("新浪K线接口不支持港股美股: %s", code)
+	}
+	symbol := sec.Symbol()
 	scale := s.klineTypeToScale(ktype)
 
 	url := fmt.Sprintf("https://quotes.sina.cn/cn/api/jsonp_v2.php/var%%20_%s_%s=/CN_MarketDataService.getKLineData?symbol=%s&scale=%s&datalen=%d",
@@ -159,7 +318,7 @@ func (s *SinaDataSource) GetKLine(ctx context.Context, code string, ktype model.
 	}
 	req.Header.Set("Referer", "https://finance.sina.com.cn")
 
-	resp, err := s.doWithRetry(req)
+	resp, err := doWithRetry(s.client, req, s.maxRetries, s.limiter)
 	if err != nil {
 		return nil, err
 	}
@@ -170,7 +329,12 @@ func (s *SinaDataSource) GetKLine(ctx context.Context, code string, ktype model.
 		return nil, err
 	}
 
-	return s.parseKLineResponse(string(body), code, ktype)
+	data, err := s.parseKLineResponse(string(body), code, ktype)
+	if err != nil {
+		return nil, err
+	}
+	markForming(data, time.Now())
+	return data, nil
 }
 
 func (s *SinaDataSource) klineTypeToScale(ktype model.KLineType) string {
@@ -194,6 +358,7 @@ func (s *SinaDataSource) klineTypeToScale(ktype model.KLineType) string {
 	}
 }
 
+// parseKLineResponse 解析K线响应，日K的 day 为 2006-01-02，分钟K为 2006-01-02 15:04:05
 func (s *SinaDataSource) parseKLineResponse(body string, code string, ktype model.KLineType) (*model.KLineData, error) {
 	start := strings.Index(body, "[")
 	end := strings.LastIndex(body, "]")
@@ -208,16 +373,19 @@ func (s *SinaDataSource) parseKLineResponse(body string, code string, ktype mode
 	}
 
 	klineData := &model.KLineData{
-		Code:  code,
-		Type:  ktype,
-		Lines: make([]model.KLine, 0, len(items)),
+		Code:   code,
+		Type:   ktype,
+		Adjust: model.AdjustNone,
+		Lines:  make([]model.KLine, 0, len(items)),
 	}
 
 	for _, item := range items {
-		kline := model.KLine{}
-		if day, ok := item["day"].(string); ok {
-			kline.Time, _ = time.ParseInLocation("2006-01-02", day, time.Local)
+		day, _ := item["day"].(string)
+		barTime, err := parseBarTime(day)
+		if err != nil {
+			return nil, err
 		}
+		kline := model.KLine{Time: barTime}
 		kline.Open = parseFloat(item["open"])
 		kline.High = parseFloat(item["high"])
 		kline.Low = parseFloat(item["low"])
@@ -226,6 +394,9 @@ func (s *SinaDataSource) parseKLineResponse(body string, code string, ktype mode
 		klineData.Lines = append(klineData.Lines, kline)
 	}
 
+	if err := normalizeKLines(klineData); err != nil {
+		return nil, err
+	}
 	return klineData, nil
 }
 
@@ -0,0 +1,145 @@
+package datasource
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+)
+
+func TestSinaParseMinuteKLine(t *testing.T) {
+	s := NewSinaDataSource()
+	data, err := s.parseKLineResponse(string(readFixture(t, "sina_kline_5min.txt")), "600519", model.KLine5Min)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 重复的 14:55 只保留最后一根
+	if len(data.Lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(data.Lines))
+	}
+	want := []string{"2024-01-02 14:50", "2024-01-02 14:55", "2024-01-02 15:00"}
+	for i, w := range want {
+		if got := data.Lines[i].Time.Format("2006-01-02 15:04"); got != w {
+			t.Errorf("line %d: got time %s, want %s", i, got, w)
+		}
+	}
+	if data.Lines[1].Close != 1689.5 || data.Lines[1].Volume != 31200 {
+		t.Errorf("duplicate bar: got close=%v vol=%d", data.Lines[1].Close, data.Lines[1].Volume)
+	}
+
+	markForming(data, time.Date(2024, 1, 2, 14, 58, 0, 0, time.Local))
+	if !data.Forming {
+		t.Error("last bar should be forming at 14:58")
+	}
+	markForming(data, time.Date(2024, 1, 2, 15, 0, 0, 0, time.Local))
+	if data.Forming {
+		t.Error("last bar should be complete at 15:00")
+	}
+}
+
+func TestSinaParseKLineOutOfOrder(t *testing.T) {
+	s := NewSinaDataSource()
+	body := `([{"day":"2024-01-03","open":"1","high":"1","low":"1","close":"1","volume":"1"},` +
+		`{"day":"2024-01-02","open":"1","high":"1","low":"1","close":"1","volume":"1"}])`
+	_, err := s.parseKLineResponse(body, "600519", model.KLineDaily)
+	if err == nil || !strings.Contains(err.Error(), "out of order") {
+		t.Fatalf("got err %v, want out of order", err)
+	}
+}
+
+func TestIsFormingBar(t *testing.T) {
+	day := time.Date(2024, 1, 5, 0, 0, 0, 0, time.Local) // 周五
+	tests := []struct {
+		ktype model.KLineType
+		bar   time.Time
+		now   time.Time
+		want  bool
+	}{
+		{model.KLineDaily, day, day.Add(10 * time.Hour), true},
+		{model.KLineDaily, day, day.Add(15 * time.Hour), false},
+		{model.KLineDaily, day.AddDate(0, 0, -1), day.Add(10 * time.Hour), false},
+		{model.KLineWeekly, day, day.Add(14 * time.Hour), true},
+		{model.KLineWeekly, day, day.AddDate(0, 0, 1), false},
+		{model.KLineMonthly, day, day.Add(16 * time.Hour), true},
+		{model.KLineMonthly, day, day.AddDate(0, 1, 0), false},
+	}
+	for _, tt := range tests {
+		if got := isFormingBar(tt.bar, tt.ktype, tt.now, security.MarketCN); got != tt.want {
+			t.Errorf("%s bar=%v now=%v: got %v, want %v", tt.ktype, tt.bar, tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestSinaParseQuoteDepth(t *testing.T) {
+	s := NewSinaDataSource()
+	stocks, err := s.parseQuoteResponse(string(readFixture(t, "sina_quote.txt")), []string{"600519", "000002"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stocks) != 2 {
+		t.Fatalf("got %d stocks, want 2", len(stocks))
+	}
+
+	st := stocks[0]
+	if st.Code != "600519" || st.Price != 1688 || st.PreClose != 1685.32 || st.Volume != 2453100 || st.Status != model.QuoteOK {
+		t.Errorf("got quote %+v", st)
+	}
+	if st.Depth == nil {
+		t.Fatal("depth is nil")
+	}
+	// 字段10-19为买一至买五的(量,价)，20-29为卖一至卖五
+	wantBids := [model.DepthLevels]model.DepthLevel{
+		{Price: 1687.99, Volume: 1000}, {Price: 1687.98, Volume: 2000}, {Price: 1687.97, Volume: 3000},
+		{Price: 1687.96, Volume: 4000}, {Price: 1687.95, Volume: 5000},
+	}
+	wantAsks := [model.DepthLevels]model.DepthLevel{
+		{Price: 1688.00, Volume: 1200}, {Price: 1688.01, Volume: 2400}, {Price: 1688.02, Volume: 3600},
+		{Price: 1688.03, Volume: 4800}, {Price: 1688.04, Volume: 6000},
+	}
+	if st.Depth.Bids != wantBids {
+		t.Errorf("bids = %+v, want %+v", st.Depth.Bids, wantBids)
+	}
+	if st.Depth.Asks != wantAsks {
+		t.Errorf("asks = %+v, want %+v", st.Depth.Asks, wantAsks)
+	}
+	if want := time.Date(2024, 1, 2, 15, 0, 3, 0, time.Local); !st.Time.Equal(want) {
+		t.Errorf("time = %v, want %v", st.Time, want)
+	}
+
+	suspended := stocks[1]
+	if suspended.Code != "000002" || suspended.Status != model.QuoteSuspended || suspended.Depth.BidVolume(0) != 0 {
+		t.Errorf("got suspended quote %+v", suspended)
+	}
+}
+
+func TestSinaParseOverseasQuote(t *testing.T) {
+	s := NewSinaDataSource()
+	body := `var hq_str_rt_hk00700="TENCENT,腾讯控股,321.000,320.000,325.400,318.800,322.400,2.400,0.750,322.200,322.400,5095530706,15823434,13.536,0.000,417.000,260.200,2024/01/02,16:08";` + "\n" +
+		`var hq_str_gb_aapl="苹果,185.6400,-3.58,2024-01-03 05:00:01,-6.8900,187.1500,188.4400,183.8850,199.6200,164.0800,82488674,54178216,2887237520000,6.13,30.280000,0.00,0.00,0.96,0.00,0.00,0,0,0,0,0,0,192.5300";` + "\n"
+	stocks, err := s.parseQuoteResponse(body, []string{"00700", "AAPL.US"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stocks) != 2 {
+		t.Fatalf("got %d stocks, want 2", len(stocks))
+	}
+
+	hk := stocks[0]
+	if hk.Code != "hk00700" || hk.Exchange != "hk" || hk.Name != "腾讯控股" || hk.Price != 322.4 || hk.PreClose != 320 || hk.Volume != 15823434 {
+		t.Errorf("got hk quote %+v", hk)
+	}
+	if want := time.Date(2024, 1, 2, 16, 8, 0, 0, security.MarketHK.Location); !hk.Time.Equal(want) {
+		t.Errorf("got hk time %v, want %v", hk.Time, want)
+	}
+
+	us := stocks[1]
+	if us.Code != "usAAPL" || us.Exchange != "us" || us.Price != 185.64 || us.PreClose != 192.53 || us.Open != 187.15 || us.Volume != 82488674 {
+		t.Errorf("got us quote %+v", us)
+	}
+	// 北京时间 05:00:01 即纽约时间前一日 16:00:01
+	if want := time.Date(2024, 1, 2, 16, 0, 1, 0, security.MarketUS.Location); !us.Time.Equal(want) {
+		t.Errorf("got us time %v, want %v", us.Time, want)
+	}
+}
@@ -0,0 +1,235 @@
+package datasource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+
+	"github.com/gorilla/websocket"
+)
+
+// StreamingDataSource 推送行情的数据源（可选接口）
+//
+// Subscribe 返回的通道在 ctx 取消后关闭；连接中断等错误由实现自行重试并记录日志。
+type StreamingDataSource interface {
+	Subscribe(ctx context.Context, codes []string) <-chan *model.Stock
+}
+
+// streamBuffer 推送通道的缓冲长度，消费者处理不及时时推送方阻塞等待
+const streamBuffer = 256
+
+// DefaultPollInterval 轮询适配器默认的请求间隔
+const DefaultPollInterval = 3 * time.Second
+
+// PollingStream 轮询适配器：按固定间隔请求任意数据源的实时行情，只推送有变化的行情
+type PollingStream struct {
+	ds       DataSource
+	interval time.Duration
+}
+
+// NewPollingStream 创建轮询适配器，interval 不大于0时使用默认间隔
+func NewPollingStream(ds DataSource, interval time.Duration) *PollingStream {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	return &PollingStream{ds: ds, interval: interval}
+}
+
+// Subscribe 订阅行情，订阅后立即请求一次
+func (p *PollingStream) Subscribe(ctx context.Context, codes []string) <-chan *model.Stock {
+	ch := make(chan *model.Stock, streamBuffer)
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		last := make(map[string]*model.Stock, len(codes))
+		for {
+			stocks, err := p.ds.GetRealTimeQuote(ctx, codes)
+			if err != nil && len(stocks) == 0 && ctx.Err() == nil {
+				slog.Warn("轮询行情失败", "source", p.ds.Name(), "error", err)
+			}
+			for _, st := range stocks {
+				if prev, ok := last[st.Code]; ok && !quoteChanged(prev, st) {
+					continue
+				}
+				last[st.Code] = st
+				select {
+				case ch <- st:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// quoteChanged 行情时间、价格或成交量有变化
+func quoteChanged(prev, cur *model.Stock) bool {
+	return !prev.Time.Equal(cur.Time) || prev.Price != cur.Price || prev.Volume != cur.Volume
+}
+
+// WebSocketStream WebSocket 推送行情
+//
+// 连接后发送 {"action":"subscribe","codes":[...]}，服务端推送的每条文本消息为一个
+// model.Stock 或其数组。连接断开后按指数退避重连并重新订阅。
+type WebSocketStream struct {
+	url        string
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	validator  *QuoteValidator // 非空时丢弃未通过校验的推送
+}
+
+// NewWebSocketStream 创建 WebSocket 推送行情，url 形如 ws://host:port/quotes
+func NewWebSocketStream(url string) *WebSocketStream {
+	return &WebSocketStream{url: url, minBackoff: time.Second, maxBackoff: 30 * time.Second}
+}
+
+// subscribeRequest 订阅请求
+type subscribeRequest struct {
+	Action string   `json:"action"`
+	Codes  []string `json:"codes"`
+}
+
+// Subscribe 订阅行情
+func (w *WebSocketStream) Subscribe(ctx context.Context, codes []string) <-chan *model.Stock {
+	ch := make(chan *model.Stock, streamBuffer)
+	go func() {
+		defer close(ch)
+		backoff := w.minBackoff
+		for {
+			received, err := w.run(ctx, codes, ch)
+			if ctx.Err() != nil {
+				return
+			}
+			if received {
+				backoff = w.minBackoff
+			}
+			slog.Warn("行情推送连接断开", "url", w.url, "error", err, "retry", backoff)
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return
+			}
+			backoff = min(backoff*2, w.maxBackoff)
+		}
+	}()
+	return ch
+}
+
+const (
+	wsMaxMessage   = 16 << 20    // 单条推送消息的最大长度
+	wsCloseTimeout = time.Second // 发送 close 帧的最长等待时间，对端失联时不阻塞关闭
+)
+
+// run 建立一次连接并持续读取推送，返回是否收到过行情
+func (w *WebSocketStream) run(ctx context.Context, codes []string, ch chan<- *model.Stock) (bool, error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, w.url, nil)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	conn.SetReadLimit(wsMaxMessage)
+	// 主动取消时发送 close 帧再断开；对端先关闭时库已应答 close 帧，只需关闭连接
+	stop := context.AfterFunc(ctx, func() {
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(wsCloseTimeout))
+		conn.Close()
+	})
+	defer stop()
+
+	if err := conn.WriteJSON(subscribeRequest{Action: "subscribe", Codes: codes}); err != nil {
+		return false, err
+	}
+
+	received := false
+	for {
+		msgType, msg, err := conn.ReadMessage()
+		if err != nil {
+			return received, err
+		}
+		if msgType != websocket.TextMessage {
+			continue
+		}
+		stocks, err := parseStreamMessage(msg)
+		if err != nil {
+			slog.Warn("解析推送行情失败", "url", w.url, "error", err)
+			continue
+		}
+		for _, st := range stocks {
+			received = true
+			if w.validator != nil {
+				if codeErr := w.validator.Check(st); codeErr != nil {
+					slog.Debug("丢弃异常推送行情", "error", codeErr)
+					continue
+				}
+			}
+			select {
+			case ch <- st:
+			case <-ctx.Done():
+				return received, ctx.Err()
+			}
+		}
+	}
+}
+
+// parseStreamMessage 解析推送消息，代码统一为规范形式并补充品种
+func parseStreamMessage(msg []byte) ([]*model.Stock, error) {
+	var stocks []*model.Stock
+	msg = bytes.TrimSpace(msg)
+	if len(msg) > 0 && msg[0] == '[' {
+		if err := json.Unmarshal(msg, &stocks); err != nil {
+			return nil, err
+		}
+	} else {
+		var st model.Stock
+		if err := json.Unmarshal(msg, &st); err != nil {
+			return nil, err
+		}
+		stocks = []*model.Stock{&st}
+	}
+
+	result := stocks[:0]
+	for _, st := range stocks {
+		if st == nil {
+			continue
+		}
+		if sec, err := security.Parse(st.Code); err == nil {
+			st.Code = sec.Canonical()
+			if st.Exchange == "" {
+				st.Exchange = string(sec.Exchange)
+			}
+			if st.Type == "" {
+				st.Type = instrumentType(sec)
+			}
+		}
+		result = append(result, st)
+	}
+	return result, nil
+}
+
+// NewStream 按配置创建推送行情：配置了 stream_url 时使用 WebSocket（推送行情同样经过校验），
+// 数据源自身支持推送时直接使用，否则轮询 ds
+func NewStream(cfg Config, ds DataSource) StreamingDataSource {
+	if cfg.StreamURL != "" {
+		ws := NewWebSocketStream(cfg.StreamURL)
+		ws.validator = NewQuoteValidator(validationOptions(cfg))
+		return ws
+	}
+	if s, ok := ds.(StreamingDataSource); ok {
+		return s
+	}
+	return NewPollingStream(ds, time.Duration(cfg.PollInterval)*time.Second)
+}
@@ -0,0 +1,178 @@
+package datasource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"stock-monitor/internal/model"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsTestServer 本地 WebSocket 推送服务：每次连接收到订阅后推送 pushes 中的下一组消息并断开
+type wsTestServer struct {
+	t       *testing.T
+	pushes  chan []string
+	subsRcv chan subscribeRequest
+}
+
+func (s *wsTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var req subscribeRequest
+	if err := conn.ReadJSON(&req); err != nil {
+		s.t.Errorf("read subscribe: %v", err)
+		return
+	}
+	s.subsRcv <- req
+
+	conn.WriteControl(websocket.PingMessage, []byte("hi"), time.Now().Add(time.Second))
+	for _, msg := range <-s.pushes {
+		conn.WriteMessage(websocket.TextMessage, []byte(msg))
+	}
+	// 正常关闭后断开，触发重连
+	conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+}
+
+func TestWebSocketStream(t *testing.T) {
+	srv := &wsTestServer{t: t, pushes: make(chan []string, 2), subsRcv: make(chan subscribeRequest, 2)}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	srv.pushes <- []string{
+		`{"code":"sh600519","name":"贵州茅台","price":1680}`,
+		`[{"code":"000001","price":10.5},{"code":"hk00700","price":320}]`,
+	}
+	srv.pushes <- []string{`{"code":"600519","price":1681}`}
+
+	stream := NewWebSocketStream("ws" + strings.TrimPrefix(ts.URL, "http"))
+	stream.minBackoff = 10 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ch := stream.Subscribe(ctx, []string{"600519", "000001", "hk00700"})
+
+	var got []*model.Stock
+	for len(got) < 4 {
+		select {
+		case st := <-ch:
+			got = append(got, st)
+		case <-ctx.Done():
+			t.Fatalf("received %d quotes before timeout", len(got))
+		}
+	}
+
+	want := []struct {
+		code  string
+		price float64
+	}{{"600519", 1680}, {"000001", 10.5}, {"hk00700", 320}, {"600519", 1681}}
+	for i, w := range want {
+		if got[i].Code != w.code || got[i].Price != w.price {
+			t.Errorf("quote %d = %s %.2f, want %s %.2f", i, got[i].Code, got[i].Price, w.code, w.price)
+		}
+	}
+	if got[2].Type != model.InstrumentStock || got[2].Exchange != "hk" {
+		t.Errorf("hk quote type/exchange = %s/%s", got[2].Type, got[2].Exchange)
+	}
+
+	for i := 0; i < 2; i++ {
+		req := <-srv.subsRcv
+		if req.Action != "subscribe" || len(req.Codes) != 3 {
+			t.Errorf("subscribe request %d = %+v", i, req)
+		}
+	}
+
+	cancel()
+	for range ch {
+	}
+}
+
+func TestWebSocketHandshakeRejected(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	stream := NewWebSocketStream("ws" + strings.TrimPrefix(ts.URL, "http"))
+	if _, err := stream.run(context.Background(), []string{"600519"}, make(chan *model.Stock)); err == nil {
+		t.Fatal("expected handshake error")
+	}
+}
+
+func TestPollingStream(t *testing.T) {
+	inner := &quoteMapDataSource{prices: map[string]float64{"600519": 1680}}
+	stream := NewPollingStream(inner, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := stream.Subscribe(ctx, []string{"600519"})
+
+	first := <-ch
+	if first.Price != 1680 {
+		t.Fatalf("first quote price = %.2f", first.Price)
+	}
+
+	// 价格不变时不推送
+	select {
+	case st := <-ch:
+		t.Fatalf("unexpected unchanged quote %+v", st)
+	case <-time.After(50 * time.Millisecond):
+	}
+	cancel()
+	for range ch {
+	}
+	if inner.calls < 2 {
+		t.Errorf("polled %d times", inner.calls)
+	}
+}
+
+func TestWebSocketStreamCancel(t *testing.T) {
+	closed := make(chan int, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// 不推送行情，只等待客户端关闭
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				code := -1
+				if ce, ok := err.(*websocket.CloseError); ok {
+					code = ce.Code
+				}
+				closed <- code
+				return
+			}
+		}
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := NewWebSocketStream("ws"+strings.TrimPrefix(ts.URL, "http")).Subscribe(ctx, []string{"600519"})
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("unexpected quote")
+		}
+	case <-time.After(3 * wsCloseTimeout):
+		t.Fatal("Subscribe did not stop after cancel")
+	}
+	select {
+	case code := <-closed:
+		if code != websocket.CloseNormalClosure {
+			t.Errorf("server saw close code %d, want %d", code, websocket.CloseNormalClosure)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("server did not see the close frame")
+	}
+}
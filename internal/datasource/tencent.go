@@ -0,0 +1,356 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+const (
+	tencentQuoteURL  = "https://qt.gtimg.cn/q="
+	tencentKLineURL  = "https://web.ifzq.gtimg.cn/appstock/app/fqkline/get"
+	tencentMinuteURL = "https://ifzq.gtimg.cn/appstock/app/kline/mkline"
+)
+
+var tencentQuoteRegexp = regexp.MustCompile(`v_([\w.$]+)="([^"]*)"`)
+
+// 腾讯行情字段下标（以 ~ 分隔）
+const (
+	tqName           = 1
+	tqCode           = 2
+	tqPrice          = 3
+	tqPreClose       = 4
+	tqOpen           = 5
+	tqOverseasVolume = 6  // 港股美股成交量 股
+	tqBid1           = 9  // 9-18 买一至买五的(价,量)，量为手
+	tqAsk1           = 19 // 19-28 卖一至卖五的(价,量)
+	tqTime           = 30
+	tqHigh           = 33
+	tqLow            = 34
+	tqVolume         = 36 // 手
+	tqAmount         = 37 // 万元
+	tqTurnover       = 38
+	tqPE             = 39
+	tqFloatCap       = 44 // 亿元
+	tqMarketCap      = 45 // 亿元
+	tqPB             = 46
+	tqLimitUp        = 47
+	tqLimitDown      = 48
+	tqMinFieldsLen   = 49
+)
+
+// TencentDataSource 腾讯行情数据源
+type TencentDataSource struct {
+	client     *http.Client
+	maxRetries int
+	adjust     model.AdjustType
+	quoteURL   string
+	klineURL   string
+	minuteURL  string
+	chunkSize  int
+	workers    int
+	limiter    *rateLimiter
+}
+
+// NewTencentDataSource 创建腾讯数据源，adjust 为日/周/月K线复权方式
+func NewTencentDataSource(adjust model.AdjustType) *TencentDataSource {
+	if adjust == "" {
+		adjust = model.AdjustNone
+	}
+	return &TencentDataSource{
+		client:     &http.Client{Timeout: 10 * time.Second},
+		maxRetries: 3,
+		adjust:     adjust,
+		quoteURL:   tencentQuoteURL,
+		klineURL:   tencentKLineURL,
+		minuteURL:  tencentMinuteURL,
+		chunkSize:  defaultChunkSize,
+		workers:    defaultQuoteWorkers,
+		limiter:    newRateLimiter(defaultRatePerSec),
+	}
+}
+
+func (t *TencentDataSource) Name() string {
+	return "tencent"
+}
+
+func (t *TencentDataSource) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Referer", "https://gu.qq.com")
+
+	resp, err := doWithRetry(t.client, req, t.maxRetries, t.limiter)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("腾讯接口返回状态码: %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// GetRealTimeQuote 批量获取实时行情，代码较多时自动分批并发请求
+func (t *TencentDataSource) GetRealTimeQuote(ctx context.Context, codes []string) ([]*model.Stock, error) {
+	return fetchChunked(ctx, codes, t.chunkSize, t.workers, t.fetchQuotes)
+}
+
+// fetchQuotes 单次请求一批代码的行情
+func (t *TencentDataSource) fetchQuotes(ctx context.Context, codes []string) ([]*model.Stock, error) {
+	symbols := make([]string, len(codes))
+	for i, code := range codes {
+		sec, err := security.Parse(code)
+		if err != nil {
+			return nil, err
+		}
+		symbols[i] = sec.Symbol()
+	}
+
+	resp, err := t.get(ctx, t.quoteURL+strings.Join(symbols, ","))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// GBK 转 UTF-8
+	reader := transform.NewReader(resp.Body, simplifiedchinese.GBK.NewDecoder())
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	stocks, err := t.parseQuoteResponse(string(body))
+	if err != nil {
+		return nil, err
+	}
+	if failed := missingCodes(codes, stocks, QuoteErrors{}); len(failed) > 0 {
+		return stocks, failed
+	}
+	return stocks, nil
+}
+
+// parseQuoteResponse 解析 v_sh600519="1~贵州茅台~600519~..." 格式的行情
+func (t *TencentDataSource) parseQuoteResponse(body string) ([]*model.Stock, error) {
+	matches := tencentQuoteRegexp.FindAllStringSubmatch(body, -1)
+
+	var stocks []*model.Stock
+	for _, match := range matches {
+		data := strings.Split(match[2], "~")
+		if len(data) < tqMinFieldsLen {
+			continue
+		}
+		sec, err := security.Parse(match[1])
+		if err != nil {
+			continue
+		}
+
+		stock := &model.Stock{
+			Code:     sec.Canonical(),
+			Exchange: string(sec.Exchange),
+			Name:     data[tqName],
+		}
+		f := func(i int) float64 {
+			v, _ := strconv.ParseFloat(data[i], 64)
+			return v
+		}
+
+		if sec.Overseas() {
+			// 港股美股成交量为股，成交额为原币种；时间为当地时间
+			stock.Price = f(tqPrice)
+			stock.Close = stock.Price
+			stock.PreClose = f(tqPreClose)
+			stock.Open = f(tqOpen)
+			stock.High = f(tqHigh)
+			stock.Low = f(tqLow)
+			stock.Volume = int64(f(tqOverseasVolume))
+			stock.Amount = f(tqAmount)
+			for _, layout := range []string{"2006/01/02 15:04:05", "2006-01-02 15:04:05"} {
+				if ts, err := time.ParseInLocation(layout, data[tqTime], sec.Market().Location); err == nil {
+					stock.Time = ts
+					break
+				}
+			}
+			stocks = append(stocks, stock)
+			continue
+		}
+
+		stock.Price = f(tqPrice)
+		stock.Close = stock.Price
+		stock.PreClose = f(tqPreClose)
+		stock.Open = f(tqOpen)
+		stock.High = f(tqHigh)
+		stock.Low = f(tqLow)
+		stock.Volume = int64(f(tqVolume)) * 100
+		stock.Amount = f(tqAmount) * 1e4
+		stock.TurnoverRate = f(tqTurnover)
+		stock.PE = f(tqPE)
+		stock.PB = f(tqPB)
+		stock.FloatMarketCap = f(tqFloatCap) * 1e8
+		stock.MarketCap = f(tqMarketCap) * 1e8
+		stock.LimitUp = f(tqLimitUp)
+		stock.LimitDown = f(tqLimitDown)
+		stock.Time, _ = time.ParseInLocation("20060102150405", data[tqTime], time.Local)
+
+		stock.Depth = &model.Depth{}
+		for i := 0; i < model.DepthLevels; i++ {
+			stock.Depth.Bids[i] = model.DepthLevel{Price: f(tqBid1 + 2*i), Volume: int64(f(tqBid1+2*i+1)) * 100}
+			stock.Depth.Asks[i] = model.DepthLevel{Price: f(tqAsk1 + 2*i), Volume: int64(f(tqAsk1+2*i+1)) * 100}
+		}
+
+		stocks = append(stocks, stock)
+	}
+
+	return stocks, nil
+}
+
+// GetKLine 获取K线数据，日/周/月K走复权接口，分钟K走分钟接口
+func (t *TencentDataSource) GetKLine(ctx context.Context, code string, ktype model.KLineType, count int) (*model.KLineData, error) {
+	sec, err := security.Parse(code)
+	if err != nil {
+		return nil, err
+	}
+	symbol := sec.Symbol()
+	period, minute := t.klinePeriod(ktype)
+
+	var url string
+	if minute {
+		url = fmt.Sprintf("%s?param=%s,%s,,%d", t.minuteURL, symbol, period, count)
+	} else {
+		url = fmt.Sprintf("%s?param=%s,%s,,,%d,%s", t.klineURL, symbol, period, count, t.adjustParam())
+	}
+
+	resp, err := t.get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	data, err := t.parseKLineResponse(body, symbol, code, ktype)
+	if err != nil {
+		return nil, err
+	}
+	markForming(data, time.Now())
+	return data, nil
+}
+
+// klinePeriod 返回腾讯K线周期参数，以及是否为分钟K线
+func (t *TencentDataSource) klinePeriod(ktype model.KLineType) (string, bool) {
+	switch ktype {
+	case model.KLine5Min:
+		return "m5", true
+	case model.KLine15Min:
+		return "m15", true
+	case model.KLine30Min:
+		return "m30", true
+	case model.KLine60Min:
+		return "m60", true
+	case model.KLineWeekly:
+		return "week", false
+	case model.KLineMonthly:
+		return "month", false
+	default:
+		return "day", false
+	}
+}
+
+func (t *TencentDataSource) adjustParam() string {
+	if t.adjust == model.AdjustNone {
+		return ""
+	}
+	return string(t.adjust)
+}
+
+// parseKLineResponse 解析K线响应
+//
+// 数据位于 data.<symbol>.<key>，复权日K的 key 为 qfqday/hfqday，分钟K为 m5 等；
+// 每根K线为 [时间, 开盘, 收盘, 最高, 最低, 成交量, ...]，A股成交量为手，港股美股为股。
+func (t *TencentDataSource) parseKLineResponse(body []byte, symbol, code string, ktype model.KLineType) (*model.KLineData, error) {
+	var resp struct {
+		Code int                                   `json:"code"`
+		Msg  string                                `json:"msg"`
+		Data map[string]map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	series, ok := resp.Data[symbol]
+	if !ok && len(resp.Data) == 1 {
+		// 美股返回的键带交易所后缀，如 usAAPL.OQ
+		for _, v := range resp.Data {
+			series, ok = v, true
+		}
+	}
+	if resp.Code != 0 || !ok {
+		return nil, fmt.Errorf("invalid kline response: %s %s", code, resp.Msg)
+	}
+
+	period, minute := t.klinePeriod(ktype)
+	adjust := model.AdjustNone
+	raw, ok := series[period]
+	if !minute && t.adjust != model.AdjustNone {
+		if adjusted, found := series[string(t.adjust)+period]; found {
+			raw, ok, adjust = adjusted, true, t.adjust
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("invalid kline response: %s missing %s", code, period)
+	}
+
+	var rows [][]interface{}
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		return nil, err
+	}
+
+	sec, _ := security.Parse(code)
+	market := sec.Market()
+	klineData := &model.KLineData{
+		Code:   code,
+		Type:   ktype,
+		Adjust: adjust,
+		Lines:  make([]model.KLine, 0, len(rows)),
+	}
+	for _, row := range rows {
+		if len(row) < 6 {
+			continue
+		}
+		ts, _ := row[0].(string)
+		layout := "2006-01-02"
+		if minute {
+			layout = "200601021504"
+		}
+		barTime, err := time.ParseInLocation(layout, ts, market.Location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kline time: %s", ts)
+		}
+		klineData.Lines = append(klineData.Lines, model.KLine{
+			Time:   barTime,
+			Open:   parseFloat(row[1]),
+			Close:  parseFloat(row[2]),
+			High:   parseFloat(row[3]),
+			Low:    parseFloat(row[4]),
+			Volume: volumeShares(parseFloat(row[5]), sec),
+		})
+	}
+	if err := normalizeKLines(klineData); err != nil {
+		return nil, err
+	}
+	return klineData, nil
+}
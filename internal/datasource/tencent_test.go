@@ -0,0 +1,126 @@
+package datasource
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+)
+
+func TestTencentGetRealTimeQuote(t *testing.T) {
+	fixture := readFixture(t, "tencent_quote.txt")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.RawQuery != "q=sh600519,sz000001" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		w.Write(fixture)
+	}))
+	defer srv.Close()
+
+	tc := NewTencentDataSource(model.AdjustNone)
+	tc.quoteURL = srv.URL + "/?q="
+	stocks, err := tc.GetRealTimeQuote(context.Background(), []string{"600519", "000001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stocks) != 2 {
+		t.Fatalf("got %d stocks, want 2", len(stocks))
+	}
+
+	s := stocks[0]
+	if s.Code != "600519" || s.Exchange != "sh" || s.Name != "贵州茅台" {
+		t.Errorf("got %s/%s/%s", s.Code, s.Exchange, s.Name)
+	}
+	checks := []struct {
+		field     string
+		got, want float64
+	}{
+		{"price", s.Price, 1688.00},
+		{"pre_close", s.PreClose, 1685.32},
+		{"open", s.Open, 1690.00},
+		{"high", s.High, 1699.00},
+		{"low", s.Low, 1681.10},
+		{"volume", float64(s.Volume), 2453100},
+		{"amount", s.Amount, 4137412400},
+		{"turnover_rate", s.TurnoverRate, 0.20},
+		{"pe", s.PE, 25.08},
+		{"pb", s.PB, 9.17},
+		{"float_market_cap", s.FloatMarketCap, 21204.97e8},
+		{"market_cap", s.MarketCap, 21204.97e8},
+		{"limit_up", s.LimitUp, 1853.85},
+		{"limit_down", s.LimitDown, 1516.79},
+	}
+	for _, c := range checks {
+		if math.Abs(c.got-c.want) > 1e-6*math.Max(1, math.Abs(c.want)) {
+			t.Errorf("%s: got %v, want %v", c.field, c.got, c.want)
+		}
+	}
+	if want := time.Date(2024, 1, 2, 15, 0, 3, 0, time.Local); !s.Time.Equal(want) {
+		t.Errorf("time: got %v, want %v", s.Time, want)
+	}
+	if stocks[1].Code != "000001" || stocks[1].Exchange != "sz" || stocks[1].Name != "平安银行" {
+		t.Errorf("got %s/%s/%s", stocks[1].Code, stocks[1].Exchange, stocks[1].Name)
+	}
+}
+
+func TestTencentParseKLine(t *testing.T) {
+	tc := NewTencentDataSource(model.AdjustQFQ)
+
+	daily, err := tc.parseKLineResponse(readFixture(t, "tencent_kline_daily.json"), "sh600519", "600519", model.KLineDaily)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if daily.Adjust != model.AdjustQFQ || len(daily.Lines) != 3 {
+		t.Fatalf("got adjust=%s lines=%d", daily.Adjust, len(daily.Lines))
+	}
+	want := model.KLine{
+		Time:   time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local),
+		Open:   1715.00,
+		Close:  1685.01,
+		High:   1718.19,
+		Low:    1678.10,
+		Volume: 3215600,
+	}
+	if daily.Lines[2] != want {
+		t.Errorf("got %+v, want %+v", daily.Lines[2], want)
+	}
+
+	minute, err := tc.parseKLineResponse(readFixture(t, "tencent_kline_5min.json"), "sz000001", "000001", model.KLine5Min)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if minute.Adjust != model.AdjustNone || len(minute.Lines) != 2 {
+		t.Fatalf("got adjust=%s lines=%d", minute.Adjust, len(minute.Lines))
+	}
+	if want := time.Date(2024, 1, 2, 9, 40, 0, 0, time.Local); !minute.Lines[1].Time.Equal(want) {
+		t.Errorf("got bar time %v, want %v", minute.Lines[1].Time, want)
+	}
+}
+
+func TestTencentParseOverseasKLine(t *testing.T) {
+	tc := NewTencentDataSource(model.AdjustQFQ)
+	data, err := tc.parseKLineResponse(readFixture(t, "tencent_kline_hk.json"), "hk00700", "hk00700", model.KLineDaily)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Adjust != model.AdjustNone || len(data.Lines) != 2 {
+		t.Fatalf("got adjust=%s lines=%d", data.Adjust, len(data.Lines))
+	}
+	// 港股成交量单位为股，时间为香港时间
+	want := model.KLine{
+		Time:   time.Date(2024, 1, 2, 0, 0, 0, 0, security.MarketHK.Location),
+		Open:   300.00,
+		Close:  295.40,
+		High:   301.20,
+		Low:    294.00,
+		Volume: 18234567,
+	}
+	if got := data.Lines[1]; !got.Time.Equal(want.Time) || got.Volume != want.Volume || got.Close != want.Close {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
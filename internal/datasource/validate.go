@@ -0,0 +1,169 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+)
+
+// ValidationOptions 行情校验配置
+type ValidationOptions struct {
+	MaxStale int // 交易时段内行情时间落后超过该分钟数视为过期，不大于0时不检查
+}
+
+// DefaultValidationOptions 默认配置
+func DefaultValidationOptions() ValidationOptions {
+	return ValidationOptions{MaxStale: 5}
+}
+
+// QuoteValidator 行情校验：剔除零价/负价、过期、超出涨跌停幅度和最高价低于最低价的行情
+type QuoteValidator struct {
+	opts ValidationOptions
+	now  func() time.Time
+}
+
+// NewQuoteValidator 创建行情校验
+func NewQuoteValidator(opts ValidationOptions) *QuoteValidator {
+	return &QuoteValidator{opts: opts, now: time.Now}
+}
+
+// Check 校验单条行情，通过时返回 nil
+//
+// 停牌且无成交价的行情保留停牌状态，其余问题记为 QuoteInvalid。
+func (v *QuoteValidator) Check(st *model.Stock) *CodeError {
+	invalid := func(format string, args ...interface{}) *CodeError {
+		return &CodeError{Code: st.Code, Status: model.QuoteInvalid, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	if st.Price <= 0 {
+		if st.Status == model.QuoteSuspended {
+			return &CodeError{Code: st.Code, Status: model.QuoteSuspended, Reason: "停牌无成交价"}
+		}
+		if st.Price == 0 {
+			return invalid("无成交价")
+		}
+		return invalid("价格为负 %.3f", st.Price)
+	}
+	if st.High > 0 && st.Low > 0 && st.High < st.Low {
+		return invalid("最高价 %.3f 低于最低价 %.3f", st.High, st.Low)
+	}
+
+	sec, err := security.Parse(st.Code)
+	if err != nil {
+		return nil
+	}
+	if ok, limit := withinLimit(st, sec); !ok {
+		return invalid("价格 %.3f 超出涨跌停范围 (昨收 %.3f，幅度 %.0f%%)", st.Price, st.PreClose, limit*100)
+	}
+
+	if v.opts.MaxStale > 0 && !st.Time.IsZero() && st.Status != model.QuoteSuspended {
+		m, now := sec.Market(), v.now()
+		if isSessionClose(m, st.Time, now) {
+			// 上一交易日的收盘行情：节假日和开盘前数据源均返回该行情，不视为过期
+			return nil
+		}
+		if lag := m.MinutesBetween(st.Time, now); lag > v.opts.MaxStale {
+			return invalid("行情时间 %s 已落后 %d 分钟", st.Time.Format(time.DateTime), lag)
+		}
+	}
+	return nil
+}
+
+// withinLimit 价格是否在涨跌停范围内，优先使用数据源提供的涨跌停价
+//
+// 无涨跌停限制的品种（指数、港股美股、上市首日等）始终返回 true。
+func withinLimit(st *model.Stock, sec security.Security) (bool, float64) {
+	const tick = 0.011 // 涨跌停价按分四舍五入
+	if st.LimitUp > 0 && st.LimitDown > 0 {
+		return st.Price <= st.LimitUp+tick && st.Price >= st.LimitDown-tick, 0
+	}
+	ratio := sec.LimitRatio(st.Name)
+	if ratio == 0 || st.PreClose <= 0 || isNewListing(st.Name) {
+		return true, ratio
+	}
+	return math.Abs(st.Price-st.PreClose) <= st.PreClose*ratio+tick, ratio
+}
+
+// isSessionClose 行情时间是否为此前某个交易日收盘及之后
+func isSessionClose(m *security.Market, t, now time.Time) bool {
+	return m.Date(t).Before(m.Date(now)) && !t.Before(m.CloseTime(m.In(t)))
+}
+
+// isNewListing 名称以 N（上市首日）或 C（注册制新股前五日）开头的股票不设涨跌幅限制
+func isNewListing(name string) bool {
+	return strings.HasPrefix(name, "N") || strings.HasPrefix(name, "C")
+}
+
+// ValidatingDataSource 行情校验数据源：未通过校验的行情从结果中剔除，并以 QuoteErrors 返回原因
+type ValidatingDataSource struct {
+	DataSource
+	validator *QuoteValidator
+}
+
+// NewValidatingDataSource 创建行情校验数据源
+func NewValidatingDataSource(ds DataSource, opts ValidationOptions) *ValidatingDataSource {
+	return &ValidatingDataSource{DataSource: ds, validator: NewQuoteValidator(opts)}
+}
+
+// GetRealTimeQuote 获取并校验实时行情
+func (d *ValidatingDataSource) GetRealTimeQuote(ctx context.Context, codes []string) ([]*model.Stock, error) {
+	stocks, err := d.DataSource.GetRealTimeQuote(ctx, codes)
+	if len(stocks) == 0 {
+		return stocks, err
+	}
+
+	// 失败原因以请求时的原始代码为键
+	requested := make(map[string]string, len(codes))
+	for _, code := range codes {
+		if sec, perr := security.Parse(code); perr == nil {
+			requested[sec.Canonical()] = code
+		}
+	}
+
+	var rejected QuoteErrors
+	valid := stocks[:0:0]
+	for _, st := range stocks {
+		codeErr := d.validator.Check(st)
+		if codeErr == nil {
+			valid = append(valid, st)
+			continue
+		}
+		if rejected == nil {
+			rejected = QuoteErrors{}
+		}
+		if code, ok := requested[st.Code]; ok {
+			codeErr.Code = code
+		}
+		rejected[codeErr.Code] = codeErr
+	}
+	if rejected == nil {
+		return stocks, err
+	}
+
+	var failed QuoteErrors
+	switch {
+	case errors.As(err, &failed):
+		for code, e := range rejected {
+			failed[code] = e
+		}
+	case err != nil:
+		// 整体错误无法按代码拆分，保留原错误
+	default:
+		err = rejected
+	}
+	if len(valid) == 0 {
+		return nil, err
+	}
+	return valid, err
+}
+
+// ListSecurities 透传内部数据源的证券列表
+func (d *ValidatingDataSource) ListSecurities(ctx context.Context) ([]security.Entry, error) {
+	return listSecurities(ctx, d.DataSource)
+}
@@ -0,0 +1,92 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stock-monitor/internal/model"
+)
+
+func TestQuoteValidator(t *testing.T) {
+	now := time.Date(2024, 1, 3, 13, 2, 0, 0, time.Local)
+	v := NewQuoteValidator(DefaultValidationOptions())
+	v.now = func() time.Time { return now }
+
+	quote := func(modify func(st *model.Stock)) *model.Stock {
+		st := &model.Stock{
+			Code: "600519", Name: "贵州茅台", Price: 1700, PreClose: 1680,
+			High: 1710, Low: 1675, Time: now.Add(-30 * time.Second),
+		}
+		if modify != nil {
+			modify(st)
+		}
+		return st
+	}
+
+	tests := []struct {
+		name   string
+		stock  *model.Stock
+		status model.QuoteStatus // 空表示通过
+	}{
+		{"正常", quote(nil), ""},
+		{"开盘前无成交", quote(func(st *model.Stock) { st.Price, st.High, st.Low = 0, 0, 0 }), model.QuoteInvalid},
+		{"停牌无成交价", quote(func(st *model.Stock) { st.Price, st.Status = 0, model.QuoteSuspended }), model.QuoteSuspended},
+		{"负价", quote(func(st *model.Stock) { st.Price = -1 }), model.QuoteInvalid},
+		{"最高低于最低", quote(func(st *model.Stock) { st.High, st.Low = 1660, 1690 }), model.QuoteInvalid},
+		{"涨停价", quote(func(st *model.Stock) { st.Price = 1848 }), ""},
+		{"超出涨停", quote(func(st *model.Stock) { st.Price = 1900 }), model.QuoteInvalid},
+		{"主板ST股10%", quote(func(st *model.Stock) { st.Name, st.PreClose, st.Price = "*ST某某", 10, 10.9 }), ""},
+		{"主板ST股超出10%", quote(func(st *model.Stock) { st.Name, st.PreClose, st.Price = "*ST某某", 10, 11.1 }), model.QuoteInvalid},
+		{"创业板ETF 20%", quote(func(st *model.Stock) { st.Code, st.Name, st.PreClose, st.Price = "159915", "创业板ETF", 2, 2.3 }), ""},
+		{"新股无限制", quote(func(st *model.Stock) { st.Name, st.Price = "N茅台", 3000 }), ""},
+		{"数据源涨跌停价", quote(func(st *model.Stock) { st.LimitUp, st.LimitDown, st.Price = 1848, 1512, 1849 }), model.QuoteInvalid},
+		{"港股无限制", quote(func(st *model.Stock) { st.Code, st.Price, st.PreClose = "hk00700", 400, 300 }), ""},
+		// 11:25 至 13:02 之间只有 5+2 个交易分钟
+		{"午休前行情", quote(func(st *model.Stock) { st.Time = now.Add(-97 * time.Minute) }), model.QuoteInvalid},
+		{"午休不计入", quote(func(st *model.Stock) { st.Time = time.Date(2024, 1, 3, 11, 29, 0, 0, time.Local) }), ""},
+		{"昨日盘中行情", quote(func(st *model.Stock) { st.Time = now.AddDate(0, 0, -1).Add(time.Hour) }), model.QuoteInvalid},
+		// 节假日或开盘前数据源返回上一交易日收盘行情
+		{"昨日收盘行情", quote(func(st *model.Stock) { st.Time = time.Date(2024, 1, 2, 15, 0, 0, 0, time.Local) }), ""},
+		{"昨日收盘后盘后", quote(func(st *model.Stock) { st.Time = now.AddDate(0, 0, -1).Add(2 * time.Hour) }), ""},
+	}
+	for _, tt := range tests {
+		err := v.Check(tt.stock)
+		switch {
+		case tt.status == "" && err != nil:
+			t.Errorf("%s: unexpected %v", tt.name, err)
+		case tt.status != "" && (err == nil || err.Status != tt.status):
+			t.Errorf("%s: got %v, want status %s", tt.name, err, tt.status)
+		}
+	}
+}
+
+func TestValidatingDataSource(t *testing.T) {
+	inner := &quoteMapDataSource{prices: map[string]float64{"600519": 1700, "000001": 0}}
+	ds := NewValidatingDataSource(inner, ValidationOptions{})
+
+	codes := []string{"600519", "000001", "sz000002"}
+	stocks, err := ds.GetRealTimeQuote(context.Background(), codes)
+	if len(stocks) != 1 || stocks[0].Code != "600519" {
+		t.Fatalf("stocks = %v", stocks)
+	}
+	var failed QuoteErrors
+	if !errors.As(err, &failed) || len(failed) != 1 {
+		t.Fatalf("err = %v", err)
+	}
+	if got := QuoteStatuses(codes, stocks, err)["000001"]; got != model.QuoteInvalid {
+		t.Errorf("000001 status = %s", got)
+	}
+}
+
+func TestNewLocalSkipsStaleCheck(t *testing.T) {
+	ds, err := New(Config{Name: "local", LocalDir: "testdata/local"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stocks, err := ds.GetRealTimeQuote(context.Background(), []string{"600519"})
+	if err != nil || len(stocks) != 1 || stocks[0].Price != 1669 {
+		t.Fatalf("stocks = %v, err = %v", stocks, err)
+	}
+}
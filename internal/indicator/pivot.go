@@ -0,0 +1,99 @@
+package indicator
+
+import (
+	"sort"
+
+	"stock-monitor/internal/model"
+)
+
+// Pivot 摆动高低点
+type Pivot struct {
+	Index  int     // K线下标
+	Price  float64 // 高点取最高价，低点取最低价
+	High   bool    // true 为摆动高点，false 为摆动低点
+	Volume int64   // 该K线成交量
+}
+
+// Pivots 查找摆动高低点：左侧 left 根、右侧 right 根K线均不超过该点
+func Pivots(lines []model.KLine, left, right int) []Pivot {
+	var pivots []Pivot
+	for i := left; i < len(lines)-right; i++ {
+		isHigh, isLow := true, true
+		for j := i - left; j <= i+right && (isHigh || isLow); j++ {
+			if j == i {
+				continue
+			}
+			if lines[j].High >= lines[i].High {
+				isHigh = false
+			}
+			if lines[j].Low <= lines[i].Low {
+				isLow = false
+			}
+		}
+		if isHigh {
+			pivots = append(pivots, Pivot{Index: i, Price: lines[i].High, High: true, Volume: lines[i].Volume})
+		}
+		if isLow {
+			pivots = append(pivots, Pivot{Index: i, Price: lines[i].Low, High: false, Volume: lines[i].Volume})
+		}
+	}
+	return pivots
+}
+
+// Zone 支撑/压力区间
+type Zone struct {
+	Low     float64 `json:"low"`
+	High    float64 `json:"high"`
+	Center  float64 `json:"center"`
+	Touches int     `json:"touches"` // 区间内的摆动点数量
+	Volume  int64   `json:"volume"`  // 摆动点成交量合计
+	Score   float64 `json:"score"`   // 强度评分
+}
+
+// Contains 价格是否落在区间内
+func (z Zone) Contains(price float64) bool {
+	return price >= z.Low && price <= z.High
+}
+
+// SRZones 将相近的摆动点聚类为支撑/压力区间，按强度降序返回
+//
+// tolerance 为聚类的价格容差百分比；评分 = 触及次数 + 区间成交量 / 摆动点平均成交量。
+func SRZones(lines []model.KLine, left, right int, tolerance float64) []Zone {
+	pivots := Pivots(lines, left, right)
+	if len(pivots) == 0 {
+		return nil
+	}
+	sort.Slice(pivots, func(i, j int) bool { return pivots[i].Price < pivots[j].Price })
+
+	var totalVol int64
+	for _, p := range pivots {
+		totalVol += p.Volume
+	}
+	avgVol := float64(totalVol) / float64(len(pivots))
+
+	var zones []Zone
+	var sum float64
+	for _, p := range pivots {
+		n := len(zones)
+		if n > 0 && p.Price <= zones[n-1].Center*(1+tolerance/100) {
+			z := &zones[n-1]
+			z.High = p.Price
+			z.Touches++
+			z.Volume += p.Volume
+			sum += p.Price
+			z.Center = sum / float64(z.Touches)
+			continue
+		}
+		sum = p.Price
+		zones = append(zones, Zone{Low: p.Price, High: p.Price, Center: p.Price, Touches: 1, Volume: p.Volume})
+	}
+
+	for i := range zones {
+		zones[i].Score = float64(zones[i].Touches)
+		if avgVol > 0 {
+			zones[i].Score += float64(zones[i].Volume) / avgVol
+		}
+	}
+	sort.SliceStable(zones, func(i, j int) bool { return zones[i].Score > zones[j].Score })
+	return zones
+}
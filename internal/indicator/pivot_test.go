@@ -0,0 +1,92 @@
+package indicator
+
+import (
+	"testing"
+
+	"stock-monitor/internal/model"
+)
+
+// swings 摆动序列：高点 12、11.9、15，低点 8、8.05
+func swings() []model.KLine {
+	hl := []struct {
+		high, low float64
+		volume    int64
+	}{
+		{10, 9, 100},
+		{12, 9.5, 100},
+		{10, 8, 100},
+		{11.9, 9.5, 100},
+		{10, 8.05, 100},
+		{15, 9.5, 600},
+		{10, 9, 100},
+	}
+	lines := make([]model.KLine, len(hl))
+	for i, b := range hl {
+		lines[i] = model.KLine{High: b.high, Low: b.low, Close: (b.high + b.low) / 2, Volume: b.volume}
+	}
+	return lines
+}
+
+func TestPivots(t *testing.T) {
+	want := []Pivot{
+		{Index: 1, Price: 12, High: true, Volume: 100},
+		{Index: 2, Price: 8, High: false, Volume: 100},
+		{Index: 3, Price: 11.9, High: true, Volume: 100},
+		{Index: 4, Price: 8.05, High: false, Volume: 100},
+		{Index: 5, Price: 15, High: true, Volume: 600},
+	}
+	got := Pivots(swings(), 1, 1)
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("pivot %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// 两侧窗口更宽时只剩最显著的点
+	if got := Pivots(swings(), 2, 1); len(got) != 2 || got[0].Price != 8 || got[1].Price != 15 {
+		t.Errorf("Pivots(2, 1) = %+v, want low 8 and high 15", got)
+	}
+
+	// 与相邻K线等高的点不算摆动点
+	flat := []model.KLine{{High: 10, Low: 9}, {High: 11, Low: 9.5}, {High: 11, Low: 9.5}, {High: 10, Low: 9}}
+	if got := Pivots(flat, 1, 1); len(got) != 0 {
+		t.Errorf("equal highs produced pivots %+v", got)
+	}
+}
+
+func TestSRZones(t *testing.T) {
+	zones := SRZones(swings(), 1, 1, 1)
+	// 8 与 8.05、11.9 与 12 在 1% 容差内各自聚为一个区间；15 成交量大，评分最高
+	want := []struct {
+		low, high, center float64
+		touches           int
+		score             float64
+	}{
+		{15, 15, 15, 1, 1 + 600.0/200},
+		{8, 8.05, 8.025, 2, 2 + 200.0/200},
+		{11.9, 12, 11.95, 2, 2 + 200.0/200},
+	}
+	if len(zones) != len(want) {
+		t.Fatalf("got %+v", zones)
+	}
+	for i, w := range want {
+		z := zones[i]
+		if z.Low != w.low || z.High != w.high || !near(z.Center, w.center) || z.Touches != w.touches || !near(z.Score, w.score) {
+			t.Errorf("zone %d = %+v, want %+v", i, z, w)
+		}
+	}
+	if !zones[1].Contains(8.03) || zones[1].Contains(8.1) {
+		t.Error("Contains boundary check failed")
+	}
+
+	// 容差为0时每个摆动点单独成区间
+	if got := SRZones(swings(), 1, 1, 0); len(got) != 5 {
+		t.Errorf("zero tolerance: got %d zones, want 5", len(got))
+	}
+	if got := SRZones(swings()[:2], 1, 1, 1); got != nil {
+		t.Errorf("too few bars: got %+v", got)
+	}
+}
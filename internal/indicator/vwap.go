@@ -0,0 +1,50 @@
+package indicator
+
+import "stock-monitor/internal/model"
+
+// VWAP 成交量加权均价（分时均价线）= 成交额 / 成交量
+func VWAP(amount float64, volume int64) float64 {
+	if volume <= 0 {
+		return 0
+	}
+	return amount / float64(volume)
+}
+
+// QuoteVWAP 由实时行情的累计成交额和成交量计算当日均价
+func QuoteVWAP(stock *model.Stock) float64 {
+	return VWAP(stock.Amount, stock.Volume)
+}
+
+// VWAPSeries 由分钟K线逐根计算累计均价
+//
+// K线不含成交额，以典型价 (最高+最低+收盘)/3 近似每根K线的成交均价。
+// 调用方应只传入同一交易日的K线。
+func VWAPSeries(lines []model.KLine) []float64 {
+	result := make([]float64, len(lines))
+	var pv float64
+	var vol int64
+	for i, k := range lines {
+		pv += (k.High + k.Low + k.Close) / 3 * float64(k.Volume)
+		vol += k.Volume
+		result[i] = VWAP(pv, vol)
+	}
+	return result
+}
+
+// LastVWAP 由分钟K线计算最后一个交易日的均价
+func LastVWAP(lines []model.KLine) float64 {
+	if len(lines) == 0 {
+		return 0
+	}
+	y, m, d := lines[len(lines)-1].Time.Date()
+	start := len(lines) - 1
+	for start > 0 {
+		py, pm, pd := lines[start-1].Time.Date()
+		if py != y || pm != m || pd != d {
+			break
+		}
+		start--
+	}
+	series := VWAPSeries(lines[start:])
+	return series[len(series)-1]
+}
@@ -0,0 +1,59 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"stock-monitor/internal/model"
+)
+
+func bar(day, hour, minute int, high, low, close float64, volume int64) model.KLine {
+	return model.KLine{
+		Time: time.Date(2024, 1, day, hour, minute, 0, 0, time.Local),
+		High: high, Low: low, Close: close, Open: close, Volume: volume,
+	}
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestVWAP(t *testing.T) {
+	if got := VWAP(1e6, 0); got != 0 {
+		t.Errorf("VWAP with zero volume = %v, want 0", got)
+	}
+	if got := QuoteVWAP(&model.Stock{Amount: 4137412400, Volume: 2453100}); !near(got, 4137412400.0/2453100) {
+		t.Errorf("QuoteVWAP = %v", got)
+	}
+}
+
+func TestVWAPSeries(t *testing.T) {
+	lines := []model.KLine{
+		bar(2, 9, 35, 10.2, 9.8, 10.0, 100),  // 典型价 10
+		bar(2, 9, 40, 10.6, 10.2, 10.4, 300), // 典型价 10.4
+		bar(2, 9, 45, 10.5, 10.3, 10.4, 0),   // 无成交不改变均价
+	}
+	want := []float64{10, 10.3, 10.3}
+	got := VWAPSeries(lines)
+	for i := range want {
+		if !near(got[i], want[i]) {
+			t.Errorf("VWAPSeries[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if len(VWAPSeries(nil)) != 0 {
+		t.Error("VWAPSeries(nil) should be empty")
+	}
+}
+
+func TestLastVWAP(t *testing.T) {
+	lines := []model.KLine{
+		bar(1, 14, 55, 20.2, 19.8, 20.0, 1000), // 前一交易日不计入
+		bar(2, 9, 35, 10.2, 9.8, 10.0, 100),
+		bar(2, 9, 40, 10.6, 10.2, 10.4, 300),
+	}
+	if got := LastVWAP(lines); !near(got, 10.3) {
+		t.Errorf("LastVWAP = %v, want 10.3", got)
+	}
+	if got := LastVWAP(nil); got != 0 {
+		t.Errorf("LastVWAP(nil) = %v, want 0", got)
+	}
+}
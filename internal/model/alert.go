@@ -22,4 +22,8 @@ type Alert struct {
 	Price     float64                `json:"price"`
 	Time      time.Time              `json:"time"`
 	Extra     map[string]interface{} `json:"extra,omitempty"`
+	Channel   string                 `json:"channel,omitempty"` // 通知频道，为空时发送到默认渠道
 }
+
+// ChannelMarket 市场整体告警的通知频道
+const ChannelMarket = "market"
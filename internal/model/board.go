@@ -0,0 +1,45 @@
+package model
+
+// BoardType 板块类型
+type BoardType string
+
+const (
+	BoardIndustry BoardType = "industry" // 行业板块
+	BoardConcept  BoardType = "concept"  // 概念板块
+)
+
+// Board 行业/概念板块及其板块指数行情
+type Board struct {
+	Code          string    `json:"code"`                     // 板块代码 如 BK1036
+	Name          string    `json:"name"`                     // 板块名称 如 半导体
+	Type          BoardType `json:"type"`                     // 板块类型
+	Price         float64   `json:"price,omitempty"`          // 板块指数点位
+	ChangePercent float64   `json:"change_percent,omitempty"` // 板块指数涨跌幅 %
+	Up            int       `json:"up,omitempty"`             // 上涨家数
+	Down          int       `json:"down,omitempty"`           // 下跌家数
+	Members       []string  `json:"members,omitempty"`        // 成分股代码，列表接口不返回时为空
+}
+
+// Group 一组股票的行情，供板块层面的规则使用
+type Group struct {
+	Code    string                // 板块代码，自选分组为空
+	Name    string                // 板块或分组名称
+	Board   *Board                // 板块数据，自选分组时为 nil
+	Members []*Stock              // 成员行情，获取失败的成员不在其中
+	KLines  map[string]*KLineData // 成员代码 -> K线，规则不需要K线时为空
+}
+
+// ChangePercent 分组涨跌幅：板块有指数行情时取指数涨跌幅，否则取成员涨跌幅的平均值
+func (g *Group) ChangePercent() float64 {
+	if g.Board != nil && g.Board.Price > 0 {
+		return g.Board.ChangePercent
+	}
+	if len(g.Members) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, st := range g.Members {
+		total += st.ChangePercent()
+	}
+	return total / float64(len(g.Members))
+}
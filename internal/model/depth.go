@@ -0,0 +1,46 @@
+package model
+
+// DepthLevels 盘口档位数
+const DepthLevels = 5
+
+// DepthLevel 单档盘口
+type DepthLevel struct {
+	Price  float64 `json:"price"`
+	Volume int64   `json:"volume"` // 股
+}
+
+// Depth 五档盘口，Bids/Asks 下标0为买一/卖一
+type Depth struct {
+	Bids [DepthLevels]DepthLevel `json:"bids"`
+	Asks [DepthLevels]DepthLevel `json:"asks"`
+}
+
+// BidVolume 前 levels 档买盘总量
+func (d *Depth) BidVolume(levels int) int64 {
+	return sumVolume(d.Bids[:], levels)
+}
+
+// AskVolume 前 levels 档卖盘总量
+func (d *Depth) AskVolume(levels int) int64 {
+	return sumVolume(d.Asks[:], levels)
+}
+
+// Imbalance 前 levels 档买卖盘失衡度 (买-卖)/(买+卖)，范围 -1~1，正值表示买盘占优
+func (d *Depth) Imbalance(levels int) float64 {
+	bid, ask := d.BidVolume(levels), d.AskVolume(levels)
+	if bid+ask == 0 {
+		return 0
+	}
+	return float64(bid-ask) / float64(bid+ask)
+}
+
+func sumVolume(levels []DepthLevel, n int) int64 {
+	if n <= 0 || n > len(levels) {
+		n = len(levels)
+	}
+	var total int64
+	for _, l := range levels[:n] {
+		total += l.Volume
+	}
+	return total
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Fundamentals 基本面数据快照
+type Fundamentals struct {
+	Code           string    `json:"code"`
+	PE             float64   `json:"pe"`               // 市盈率(TTM)，亏损时为负，未知为0
+	PB             float64   `json:"pb"`               // 市净率
+	MarketCap      float64   `json:"market_cap"`       // 总市值 元
+	FloatMarketCap float64   `json:"float_market_cap"` // 流通市值 元
+	TurnoverRate   float64   `json:"turnover_rate"`    // 换手率 %
+	Time           time.Time `json:"time"`             // 数据时间
+}
+
+// FundamentalsOf 取出行情中的基本面字段，行情不含这些字段时返回 nil
+func FundamentalsOf(st *Stock) *Fundamentals {
+	if st.PE == 0 && st.PB == 0 && st.MarketCap == 0 && st.TurnoverRate == 0 {
+		return nil
+	}
+	return &Fundamentals{
+		Code:           st.Code,
+		PE:             st.PE,
+		PB:             st.PB,
+		MarketCap:      st.MarketCap,
+		FloatMarketCap: st.FloatMarketCap,
+		TurnoverRate:   st.TurnoverRate,
+		Time:           st.Time,
+	}
+}
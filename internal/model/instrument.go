@@ -0,0 +1,49 @@
+package model
+
+// InstrumentType 证券品种
+type InstrumentType string
+
+const (
+	InstrumentStock InstrumentType = "stock" // 股票
+	InstrumentIndex InstrumentType = "index" // 指数
+	InstrumentETF   InstrumentType = "etf"   // ETF
+	InstrumentFund  InstrumentType = "fund"  // LOF/封闭式基金
+	InstrumentBond  InstrumentType = "bond"  // 可转债
+)
+
+// BondInfo 可转债数据
+type BondInfo struct {
+	UnderlyingCode  string  `json:"underlying_code"`  // 正股代码
+	UnderlyingName  string  `json:"underlying_name"`  // 正股名称
+	UnderlyingPrice float64 `json:"underlying_price"` // 正股价格
+	ConversionPrice float64 `json:"conversion_price"` // 转股价
+	ConversionValue float64 `json:"conversion_value"` // 转股价值 = 100 / 转股价 × 正股价
+	Premium         float64 `json:"premium"`          // 转股溢价率 % = (转债价格 / 转股价值 - 1) × 100
+}
+
+// NewBondInfo 由转债价格、正股价格和转股价计算转股价值与溢价率
+func NewBondInfo(price, underlyingPrice, conversionPrice float64) *BondInfo {
+	info := &BondInfo{UnderlyingPrice: underlyingPrice, ConversionPrice: conversionPrice}
+	if conversionPrice > 0 {
+		info.ConversionValue = 100 / conversionPrice * underlyingPrice
+	}
+	if info.ConversionValue > 0 && price > 0 {
+		info.Premium = (price/info.ConversionValue - 1) * 100
+	}
+	return info
+}
+
+// ETFInfo ETF数据
+type ETFInfo struct {
+	IOPV    float64 `json:"iopv"`    // 基金份额参考净值
+	Premium float64 `json:"premium"` // 溢价率 % = (现价 / IOPV - 1) × 100，负数为折价
+}
+
+// NewETFInfo 由现价和参考净值计算溢价率
+func NewETFInfo(price, iopv float64) *ETFInfo {
+	info := &ETFInfo{IOPV: iopv}
+	if iopv > 0 && price > 0 {
+		info.Premium = (price/iopv - 1) * 100
+	}
+	return info
+}
@@ -6,6 +6,7 @@ import "time"
 type KLineType string
 
 const (
+	KLine1Min    KLineType = "1min"    // 1分钟K，由实时行情合成
 	KLine5Min    KLineType = "5min"    // 5分钟K
 	KLine15Min   KLineType = "15min"   // 15分钟K
 	KLine30Min   KLineType = "30min"   // 30分钟K
@@ -15,6 +16,32 @@ const (
 	KLineMonthly KLineType = "monthly" // 月K
 )
 
+// Minutes 分钟K线的周期分钟数，日K及以上返回0
+func (t KLineType) Minutes() int {
+	switch t {
+	case KLine1Min:
+		return 1
+	case KLine5Min:
+		return 5
+	case KLine15Min:
+		return 15
+	case KLine30Min:
+		return 30
+	case KLine60Min:
+		return 60
+	}
+	return 0
+}
+
+// AdjustType 复权方式
+type AdjustType string
+
+const (
+	AdjustNone AdjustType = "none" // 不复权
+	AdjustQFQ  AdjustType = "qfq"  // 前复权
+	AdjustHFQ  AdjustType = "hfq"  // 后复权
+)
+
 // KLine K线数据
 type KLine struct {
 	Time   time.Time `json:"time"`
@@ -27,7 +54,17 @@ type KLine struct {
 
 // KLineData K线数据集合
 type KLineData struct {
-	Code  string    `json:"code"`
-	Type  KLineType `json:"type"`
-	Lines []KLine   `json:"lines"`
+	Code   string     `json:"code"`
+	Type   KLineType  `json:"type"`
+	Adjust AdjustType `json:"adjust"` // 复权方式，空值等同于不复权
+	Lines  []KLine    `json:"lines"`
+
+	// Forming 最后一根K线是否仍在形成（盘中未收盘），规则可据此排除未完成K线
+	Forming bool `json:"forming,omitempty"`
+}
+
+// AdjustFactor 复权因子（自某日起生效的累计后复权因子）
+type AdjustFactor struct {
+	Date   time.Time `json:"date"`
+	Factor float64   `json:"factor"`
 }
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// MarketOverview 沪深市场概况
+type MarketOverview struct {
+	Time      time.Time `json:"time"`
+	Advancing int       `json:"advancing"`  // 上涨家数
+	Declining int       `json:"declining"`  // 下跌家数
+	Unchanged int       `json:"unchanged"`  // 平盘家数
+	Suspended int       `json:"suspended"`  // 停牌或无成交家数
+	LimitUp   int       `json:"limit_up"`   // 涨停家数
+	LimitDown int       `json:"limit_down"` // 跌停家数
+	Amount    float64   `json:"amount"`     // 两市成交额 元
+	Indices   []*Stock  `json:"indices"`    // 主要指数行情
+}
+
+// AdvanceRatio 上涨家数占有成交家数的比例 %
+func (m *MarketOverview) AdvanceRatio() float64 {
+	total := m.Advancing + m.Declining + m.Unchanged
+	if total == 0 {
+		return 0
+	}
+	return float64(m.Advancing) / float64(total) * 100
+}
+
+// Index 按代码查找指数行情，没有时返回 nil
+func (m *MarketOverview) Index(code string) *Stock {
+	for _, st := range m.Indices {
+		if st.Code == code {
+			return st
+		}
+	}
+	return nil
+}
@@ -1,12 +1,28 @@
 package model
 
-import "time"
+import (
+	"strings"
+	"time"
+)
+
+// QuoteStatus 行情状态
+type QuoteStatus string
+
+const (
+	QuoteOK        QuoteStatus = "ok"        // 正常
+	QuoteSuspended QuoteStatus = "suspended" // 停牌
+	QuoteDelisted  QuoteStatus = "delisted"  // 退市
+	QuoteUnknown   QuoteStatus = "unknown"   // 无该代码
+	QuoteMalformed QuoteStatus = "malformed" // 数据格式异常
+	QuoteError     QuoteStatus = "error"     // 请求失败
+	QuoteInvalid   QuoteStatus = "invalid"   // 数据异常，未通过校验
+)
 
 // Stock 股票基本信息
 type Stock struct {
-	Code     string    `json:"code"`      // 股票代码 如 600519
+	Code     string    `json:"code"`      // 股票代码 如 600519，与个股重叠的指数带前缀 如 sh000001，港股美股带前缀 如 hk00700、usAAPL
 	Name     string    `json:"name"`      // 股票名称
-	Exchange string    `json:"exchange"`  // 交易所 sh/sz
+	Exchange string    `json:"exchange"`  // 交易所 sh/sz/bj/hk/us
 	Price    float64   `json:"price"`     // 当前价格
 	Open     float64   `json:"open"`      // 开盘价
 	High     float64   `json:"high"`      // 最高价
@@ -16,10 +32,30 @@ type Stock struct {
 	Volume   int64     `json:"volume"`    // 成交量
 	Amount   float64   `json:"amount"`    // 成交额
 	Time     time.Time `json:"time"`      // 行情时间
+
+	Status QuoteStatus    `json:"status,omitempty"` // 行情状态，空值等同于正常
+	Type   InstrumentType `json:"type,omitempty"`   // 证券品种，无法识别时为空
+	Depth  *Depth         `json:"depth,omitempty"`  // 五档盘口，数据源不提供时为 nil
+
+	// 品种专属数据，仅对应品种且数据可用时非 nil
+	Bond *BondInfo `json:"bond,omitempty"` // 可转债
+	ETF  *ETFInfo  `json:"etf,omitempty"`  // ETF
+
+	// 扩展行情字段，数据源不提供时为0
+	TurnoverRate   float64 `json:"turnover_rate,omitempty"`    // 换手率 %
+	PE             float64 `json:"pe,omitempty"`               // 市盈率(TTM)
+	PB             float64 `json:"pb,omitempty"`               // 市净率
+	MarketCap      float64 `json:"market_cap,omitempty"`       // 总市值 元
+	FloatMarketCap float64 `json:"float_market_cap,omitempty"` // 流通市值 元
+	LimitUp        float64 `json:"limit_up,omitempty"`         // 涨停价
+	LimitDown      float64 `json:"limit_down,omitempty"`       // 跌停价
 }
 
 // FullCode 返回完整股票代码 (带交易所前缀)
 func (s *Stock) FullCode() string {
+	if strings.HasPrefix(s.Code, s.Exchange) {
+		return s.Code
+	}
 	return s.Exchange + s.Code
 }
 
@@ -7,11 +7,15 @@ import (
 	"time"
 
 	"stock-monitor/internal/model"
+	"stock-monitor/internal/storage"
 )
 
 // Manager 通知管理器
+//
+// 告警带 Channel 且该频道配置了专属渠道时只发送到专属渠道，否则发送到默认渠道。
 type Manager struct {
 	notifiers []Notifier
+	channels  map[string][]Notifier
 	mu        sync.RWMutex
 }
 
@@ -19,9 +23,27 @@ type Manager struct {
 func NewManager() *Manager {
 	return &Manager{
 		notifiers: make([]Notifier, 0),
+		channels:  make(map[string][]Notifier),
 	}
 }
 
+// NewManagerFromConfig 按通知配置创建通知管理器
+//
+// 启用「大盘告警」时，市场整体告警（model.ChannelMarket）只发送到该飞书群。
+func NewManagerFromConfig(cfg storage.NotifierConfig) *Manager {
+	m := NewManager()
+	if cfg.ServerChan.Enabled && cfg.ServerChan.SendKey != "" {
+		m.Add(NewServerChan(cfg.ServerChan.SendKey))
+	}
+	if cfg.Feishu.Enabled && cfg.Feishu.Webhook != "" {
+		m.Add(NewFeishu(cfg.Feishu.Webhook))
+	}
+	if cfg.Market.Enabled && cfg.Market.Webhook != "" {
+		m.AddChannel(model.ChannelMarket, NewFeishu(cfg.Market.Webhook))
+	}
+	return m
+}
+
 // Add 添加通知渠道
 func (m *Manager) Add(n Notifier) {
 	m.mu.Lock()
@@ -29,11 +51,22 @@ func (m *Manager) Add(n Notifier) {
 	m.notifiers = append(m.notifiers, n)
 }
 
-// Notify 发送通知到所有渠道（单个通知超时30秒）
+// AddChannel 添加频道专属通知渠道，如 model.ChannelMarket
+func (m *Manager) AddChannel(channel string, n Notifier) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.channels[channel] = append(m.channels[channel], n)
+}
+
+// Notify 发送通知到告警所属频道的渠道（单个通知超时30秒）
 func (m *Manager) Notify(ctx context.Context, alert *model.Alert) error {
 	m.mu.RLock()
-	notifiers := make([]Notifier, len(m.notifiers))
-	copy(notifiers, m.notifiers)
+	targets := m.notifiers
+	if dedicated := m.channels[alert.Channel]; alert.Channel != "" && len(dedicated) > 0 {
+		targets = dedicated
+	}
+	notifiers := make([]Notifier, len(targets))
+	copy(notifiers, targets)
 	m.mu.RUnlock()
 
 	var wg sync.WaitGroup
@@ -0,0 +1,72 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/storage"
+)
+
+// recorder 记录收到的告警
+type recorder struct {
+	name string
+	mu   sync.Mutex
+	got  []string
+}
+
+func (r *recorder) Name() string { return r.name }
+
+func (r *recorder) Send(ctx context.Context, alert *model.Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.got = append(r.got, alert.RuleName)
+	return nil
+}
+
+func TestManagerChannels(t *testing.T) {
+	def, market := &recorder{name: "default"}, &recorder{name: "market"}
+	m := NewManager()
+	m.Add(def)
+	m.AddChannel(model.ChannelMarket, market)
+
+	ctx := context.Background()
+	m.Notify(ctx, &model.Alert{RuleName: "个股"})
+	m.Notify(ctx, &model.Alert{RuleName: "大盘", Channel: model.ChannelMarket})
+	m.Notify(ctx, &model.Alert{RuleName: "其他频道", Channel: "other"}) // 没有专属渠道时发送到默认渠道
+
+	if len(def.got) != 2 || def.got[0] != "个股" || def.got[1] != "其他频道" {
+		t.Errorf("default got %v", def.got)
+	}
+	if len(market.got) != 1 || market.got[0] != "大盘" {
+		t.Errorf("market got %v", market.got)
+	}
+}
+
+func TestNewManagerFromConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         storage.NotifierConfig
+		wantDefault int
+		wantMarket  int
+	}{
+		{"未启用", storage.NotifierConfig{}, 0, 0},
+		{"默认渠道", storage.NotifierConfig{
+			ServerChan: storage.ServerChanConfig{Enabled: true, SendKey: "k"},
+			Feishu:     storage.FeishuConfig{Enabled: true, Webhook: "https://example.com/hook"},
+		}, 2, 0},
+		{"大盘告警", storage.NotifierConfig{
+			Feishu: storage.FeishuConfig{Enabled: true, Webhook: "https://example.com/hook"},
+			Market: storage.FeishuConfig{Enabled: true, Webhook: "https://example.com/market"},
+		}, 1, 1},
+		{"大盘告警缺少地址", storage.NotifierConfig{Market: storage.FeishuConfig{Enabled: true}}, 0, 0},
+	}
+	for _, tt := range tests {
+		m := NewManagerFromConfig(tt.cfg)
+		if len(m.notifiers) != tt.wantDefault || len(m.channels[model.ChannelMarket]) != tt.wantMarket {
+			t.Errorf("%s: default=%d market=%d, want %d/%d", tt.name,
+				len(m.notifiers), len(m.channels[model.ChannelMarket]), tt.wantDefault, tt.wantMarket)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package pattern
+
+import (
+	"math"
+
+	"stock-monitor/internal/model"
+)
+
+// Type K线形态类型
+type Type string
+
+const (
+	Hammer             Type = "hammer"               // 锤子线
+	ShootingStar       Type = "shooting_star"        // 射击之星
+	BullishEngulfing   Type = "bullish_engulfing"    // 看涨吞没
+	BearishEngulfing   Type = "bearish_engulfing"    // 看跌吞没
+	Doji               Type = "doji"                 // 十字星
+	MorningStar        Type = "morning_star"         // 早晨之星
+	EveningStar        Type = "evening_star"         // 黄昏之星
+	ThreeWhiteSoldiers Type = "three_white_soldiers" // 红三兵
+	LongLowerShadow    Type = "long_lower_shadow"    // 长下影线
+)
+
+// AllTypes 全部支持的形态
+var AllTypes = []Type{
+	Hammer, ShootingStar, BullishEngulfing, BearishEngulfing, Doji,
+	MorningStar, EveningStar, ThreeWhiteSoldiers, LongLowerShadow,
+}
+
+var typeNames = map[Type]string{
+	Hammer:             "锤子线",
+	ShootingStar:       "射击之星",
+	BullishEngulfing:   "看涨吞没",
+	BearishEngulfing:   "看跌吞没",
+	Doji:               "十字星",
+	MorningStar:        "早晨之星",
+	EveningStar:        "黄昏之星",
+	ThreeWhiteSoldiers: "红三兵",
+	LongLowerShadow:    "长下影线",
+}
+
+// DisplayName 形态中文名
+func (t Type) DisplayName() string {
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	return string(t)
+}
+
+// Valid 是否为支持的形态
+func (t Type) Valid() bool {
+	_, ok := typeNames[t]
+	return ok
+}
+
+// Options 形态识别阈值，比例均相对于当根K线振幅(最高-最低)
+type Options struct {
+	DojiBodyRatio    float64 // 实体/振幅不超过该值视为十字星
+	SmallBodyRatio   float64 // 实体/振幅不超过该值视为小实体
+	LongBodyRatio    float64 // 实体/振幅不低于该值视为大实体
+	LongShadowRatio  float64 // 长影线至少为实体的倍数
+	ShortShadowRatio float64 // 短影线/振幅上限
+	TrendBars        int     // 判断前期趋势的K线数，0表示不检查趋势
+}
+
+// DefaultOptions 默认阈值
+func DefaultOptions() Options {
+	return Options{
+		DojiBodyRatio:    0.1,
+		SmallBodyRatio:   0.3,
+		LongBodyRatio:    0.6,
+		LongShadowRatio:  2,
+		ShortShadowRatio: 0.1,
+		TrendBars:        5,
+	}
+}
+
+// Match 识别到的形态，Start/End 为K线下标（闭区间）
+type Match struct {
+	Type  Type
+	Start int
+	End   int
+}
+
+// candle 单根K线的实体与影线
+type candle struct {
+	open, close float64
+	body        float64
+	rng         float64
+	upper       float64
+	lower       float64
+}
+
+func newCandle(k model.KLine) candle {
+	return candle{
+		open:  k.Open,
+		close: k.Close,
+		body:  math.Abs(k.Close - k.Open),
+		rng:   k.High - k.Low,
+		upper: k.High - math.Max(k.Open, k.Close),
+		lower: math.Min(k.Open, k.Close) - k.Low,
+	}
+}
+
+func (c candle) bullish() bool   { return c.close > c.open }
+func (c candle) bearish() bool   { return c.close < c.open }
+func (c candle) top() float64    { return math.Max(c.open, c.close) }
+func (c candle) bottom() float64 { return math.Min(c.open, c.close) }
+
+func (c candle) bodyRatio() float64 {
+	if c.rng <= 0 {
+		return 0
+	}
+	return c.body / c.rng
+}
+
+// Detect 识别以第 i 根K线结束的所有形态
+func Detect(lines []model.KLine, i int, opts Options) []Match {
+	if i < 0 || i >= len(lines) {
+		return nil
+	}
+
+	var matches []Match
+	add := func(t Type, span int) {
+		matches = append(matches, Match{Type: t, Start: i - span + 1, End: i})
+	}
+
+	cur := newCandle(lines[i])
+	if cur.rng <= 0 {
+		return nil
+	}
+
+	if cur.bodyRatio() <= opts.DojiBodyRatio {
+		add(Doji, 1)
+	}
+	if cur.lower >= opts.LongShadowRatio*cur.body && cur.lower >= cur.rng/2 {
+		add(LongLowerShadow, 1)
+	}
+	if cur.bodyRatio() <= opts.SmallBodyRatio &&
+		cur.lower >= opts.LongShadowRatio*cur.body &&
+		cur.upper <= opts.ShortShadowRatio*cur.rng &&
+		inTrend(lines, i, opts.TrendBars, -1) {
+		add(Hammer, 1)
+	}
+	if cur.bodyRatio() <= opts.SmallBodyRatio &&
+		cur.upper >= opts.LongShadowRatio*cur.body &&
+		cur.lower <= opts.ShortShadowRatio*cur.rng &&
+		inTrend(lines, i, opts.TrendBars, 1) {
+		add(ShootingStar, 1)
+	}
+
+	if i >= 1 {
+		prev := newCandle(lines[i-1])
+		if prev.bearish() && cur.bullish() &&
+			cur.open <= prev.close && cur.close >= prev.open && cur.body > prev.body {
+			add(BullishEngulfing, 2)
+		}
+		if prev.bullish() && cur.bearish() &&
+			cur.open >= prev.close && cur.close <= prev.open && cur.body > prev.body {
+			add(BearishEngulfing, 2)
+		}
+	}
+
+	if i >= 2 {
+		first := newCandle(lines[i-2])
+		star := newCandle(lines[i-1])
+		smallStar := (star.rng > 0 && star.bodyRatio() <= opts.SmallBodyRatio) || star.body <= first.body*opts.SmallBodyRatio
+		if first.bearish() && first.bodyRatio() >= opts.LongBodyRatio && smallStar &&
+			star.top() <= first.close && cur.bullish() &&
+			cur.close >= (first.open+first.close)/2 {
+			add(MorningStar, 3)
+		}
+		if first.bullish() && first.bodyRatio() >= opts.LongBodyRatio && smallStar &&
+			star.bottom() >= first.close && cur.bearish() &&
+			cur.close <= (first.open+first.close)/2 {
+			add(EveningStar, 3)
+		}
+		if threeWhiteSoldiers(lines[i-2:i+1], opts) {
+			add(ThreeWhiteSoldiers, 3)
+		}
+	}
+
+	return matches
+}
+
+// threeWhiteSoldiers 三根连续阳线，逐级抬高，开盘在前一根实体内，上影线短
+func threeWhiteSoldiers(lines []model.KLine, opts Options) bool {
+	for j, k := range lines {
+		c := newCandle(k)
+		if !c.bullish() || c.bodyRatio() < opts.LongBodyRatio/2 || c.upper > c.rng*opts.SmallBodyRatio {
+			return false
+		}
+		if j == 0 {
+			continue
+		}
+		prev := newCandle(lines[j-1])
+		if c.close <= prev.close || c.open < prev.open || c.open > prev.close {
+			return false
+		}
+	}
+	return true
+}
+
+// inTrend 第 i 根K线之前 bars 根的收盘价是否沿 dir 方向(1上涨/-1下跌)运行，bars 为0时不检查
+func inTrend(lines []model.KLine, i, bars, dir int) bool {
+	if bars <= 0 {
+		return true
+	}
+	if i-bars < 0 {
+		return false
+	}
+	diff := lines[i-1].Close - lines[i-bars].Close
+	return diff*float64(dir) > 0
+}
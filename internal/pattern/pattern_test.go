@@ -0,0 +1,107 @@
+package pattern
+
+import (
+	"testing"
+
+	"stock-monitor/internal/model"
+)
+
+func k(open, high, low, close float64) model.KLine {
+	return model.KLine{Open: open, High: high, Low: low, Close: close}
+}
+
+// downtrend 五根逐级下跌的K线，用于满足锤子线的前期趋势
+func downtrend() []model.KLine {
+	var lines []model.KLine
+	for i := 0; i < 5; i++ {
+		p := 12 - float64(i)*0.4
+		lines = append(lines, k(p, p+0.1, p-0.5, p-0.4))
+	}
+	return lines
+}
+
+// uptrend 五根逐级上涨的K线，用于满足射击之星的前期趋势
+func uptrend() []model.KLine {
+	var lines []model.KLine
+	for i := 0; i < 5; i++ {
+		p := 8 + float64(i)*0.4
+		lines = append(lines, k(p, p+0.5, p-0.1, p+0.4))
+	}
+	return lines
+}
+
+// find 返回指定类型的形态
+func find(matches []Match, t Type) (Match, bool) {
+	for _, m := range matches {
+		if m.Type == t {
+			return m, true
+		}
+	}
+	return Match{}, false
+}
+
+func has(matches []Match, t Type) bool {
+	_, ok := find(matches, t)
+	return ok
+}
+
+func TestDetect(t *testing.T) {
+	noTrend := DefaultOptions()
+	noTrend.TrendBars = 0
+
+	tests := []struct {
+		name  string
+		lines []model.KLine
+		opts  Options
+		want  Type
+		match bool
+	}{
+		{"看涨吞没", []model.KLine{k(10, 10.2, 9.4, 9.5), k(9.4, 10.4, 9.3, 10.3)}, noTrend, BullishEngulfing, true},
+		{"阳线未完全吞没", []model.KLine{k(10, 10.2, 9.4, 9.5), k(9.6, 10.0, 9.5, 9.9)}, noTrend, BullishEngulfing, false},
+		{"看跌吞没", []model.KLine{k(9.5, 10.1, 9.4, 10), k(10.1, 10.2, 9.3, 9.4)}, noTrend, BearishEngulfing, true},
+		{"早晨之星", []model.KLine{k(10, 10.1, 8.9, 9), k(8.9, 9.0, 8.7, 8.85), k(8.9, 9.8, 8.85, 9.7)}, noTrend, MorningStar, true},
+		{"早晨之星收盘未过中点", []model.KLine{k(10, 10.1, 8.9, 9), k(8.9, 9.0, 8.7, 8.85), k(8.9, 9.4, 8.85, 9.3)}, noTrend, MorningStar, false},
+		{"黄昏之星", []model.KLine{k(9, 10.1, 8.9, 10), k(10.1, 10.3, 10.0, 10.15), k(10.1, 10.15, 9.2, 9.3)}, noTrend, EveningStar, true},
+		{"十字星", []model.KLine{k(10, 10.5, 9.5, 10.01)}, noTrend, Doji, true},
+		{"下跌后锤子线", append(downtrend(), k(10, 10.02, 9.2, 10.01)), DefaultOptions(), Hammer, true},
+		{"无下跌趋势的锤子线", []model.KLine{k(10, 10.02, 9.2, 10.01)}, DefaultOptions(), Hammer, false},
+		{"上涨后射击之星", append(uptrend(), k(10, 10.8, 9.99, 10.01)), DefaultOptions(), ShootingStar, true},
+		{"下跌后的倒锤不是射击之星", append(downtrend(), k(10, 10.8, 9.99, 10.01)), DefaultOptions(), ShootingStar, false},
+		{"长下影线", []model.KLine{k(10, 10.3, 9.2, 10.2)}, noTrend, LongLowerShadow, true},
+		{"下影线不足实体两倍", []model.KLine{k(10, 10.3, 9.8, 10.2)}, noTrend, LongLowerShadow, false},
+		{"红三兵", []model.KLine{k(10, 10.55, 9.95, 10.5), k(10.3, 10.85, 10.25, 10.8), k(10.6, 11.15, 10.55, 11.1)}, noTrend, ThreeWhiteSoldiers, true},
+		{"一字板无振幅", []model.KLine{k(10, 10, 10, 10)}, noTrend, Doji, false},
+	}
+	for _, tt := range tests {
+		matches := Detect(tt.lines, len(tt.lines)-1, tt.opts)
+		if got := has(matches, tt.want); got != tt.match {
+			t.Errorf("%s: %s matched = %v, want %v (matches %+v)", tt.name, tt.want, got, tt.match, matches)
+		}
+	}
+}
+
+func TestDetectSpan(t *testing.T) {
+	tests := []struct {
+		name       string
+		lines      []model.KLine
+		want       Type
+		start, end int
+	}{
+		{"早晨之星", []model.KLine{k(10, 10.1, 8.9, 9), k(8.9, 9.0, 8.7, 8.85), k(8.9, 9.8, 8.85, 9.7)}, MorningStar, 0, 2},
+		{"射击之星", append(uptrend(), k(10, 10.8, 9.99, 10.01)), ShootingStar, 5, 5},
+		{"长下影线", append(downtrend(), k(10, 10.3, 9.2, 10.2)), LongLowerShadow, 5, 5},
+	}
+	for _, tt := range tests {
+		m, ok := find(Detect(tt.lines, len(tt.lines)-1, DefaultOptions()), tt.want)
+		if !ok {
+			t.Fatalf("%s: %s not detected", tt.name, tt.want)
+		}
+		if m.Start != tt.start || m.End != tt.end {
+			t.Errorf("%s: span = %d..%d, want %d..%d", tt.name, m.Start, m.End, tt.start, tt.end)
+		}
+	}
+
+	if Detect(uptrend()[:3], 3, DefaultOptions()) != nil {
+		t.Error("out of range index should return nil")
+	}
+}
@@ -43,24 +43,88 @@ func (e *Engine) Evaluate(ctx context.Context, ruleCtx *RuleContext) ([]*model.A
 
 	var alerts []*model.Alert
 	for _, rule := range rules {
+		if !applies(rule, ruleCtx) {
+			continue
+		}
 		result, err := rule.Evaluate(ctx, ruleCtx)
 		if err != nil {
 			continue
 		}
 		if result.Triggered {
 			alert := &model.Alert{
-				ID:        uuid.New().String(),
-				StockCode: ruleCtx.Stock.Code,
-				StockName: ruleCtx.Stock.Name,
-				RuleName:  result.RuleName,
-				Level:     result.Level,
-				Message:   result.Message,
-				Price:     ruleCtx.Stock.Price,
-				Time:      time.Now(),
-				Extra:     result.Extra,
+				ID:       uuid.New().String(),
+				RuleName: result.RuleName,
+				Level:    result.Level,
+				Message:  result.Message,
+				Time:     time.Now(),
+				Extra:    result.Extra,
+			}
+			if st := ruleCtx.Stock; st != nil {
+				alert.StockCode, alert.StockName, alert.Price = st.Code, st.Name, st.Price
+			} else if g := ruleCtx.Group; g != nil {
+				alert.StockCode, alert.StockName = g.Code, g.Name
+				if g.Board != nil {
+					alert.Price = g.Board.Price
+				}
+			} else if ruleCtx.Market != nil {
+				alert.StockName = "沪深市场"
+				alert.Channel = model.ChannelMarket
 			}
 			alerts = append(alerts, alert)
 		}
 	}
 	return alerts, nil
 }
+
+// ContextBuilder 为推送的行情构建规则上下文（如补充K线、基本面数据），返回 nil 时跳过该行情
+type ContextBuilder func(ctx context.Context, stock *model.Stock) *RuleContext
+
+// EvaluateStream 逐条评估推送的行情，每条行情触发的告警交给 handle
+//
+// build 为 nil 时只以行情构建上下文。quotes 关闭或 ctx 取消后返回。
+func (e *Engine) EvaluateStream(ctx context.Context, quotes <-chan *model.Stock, build ContextBuilder, handle func([]*model.Alert)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case st, ok := <-quotes:
+			if !ok {
+				return
+			}
+			ruleCtx := &RuleContext{Stock: st}
+			if build != nil {
+				if ruleCtx = build(ctx, st); ruleCtx == nil {
+					continue
+				}
+			}
+			alerts, _ := e.Evaluate(ctx, ruleCtx)
+			if len(alerts) > 0 {
+				handle(alerts)
+			}
+		}
+	}
+}
+
+// applies 规则是否适用于该上下文：市场规则只评估市场概况，分组规则只评估分组数据，个股规则只评估个股行情
+func applies(rule Rule, ruleCtx *RuleContext) bool {
+	switch rule.(type) {
+	case MarketRule:
+		return ruleCtx.Market != nil && ruleCtx.Stock == nil
+	case GroupRule:
+		return ruleCtx.Group != nil && ruleCtx.Stock == nil
+	}
+	return ruleCtx.Stock != nil
+}
+
+// GroupRules 返回所有分组规则，调用方据此获取分组数据
+func (e *Engine) GroupRules() []GroupRule {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	var result []GroupRule
+	for _, rule := range e.rules {
+		if g, ok := rule.(GroupRule); ok {
+			result = append(result, g)
+		}
+	}
+	return result
+}
@@ -0,0 +1,118 @@
+package rule
+
+import (
+	"context"
+	"testing"
+
+	"stock-monitor/internal/model"
+)
+
+// priceRule 现价高于阈值时触发
+type priceRule struct {
+	threshold float64
+}
+
+func (r *priceRule) Name() string        { return "price" }
+func (r *priceRule) Description() string { return "price" }
+func (r *priceRule) Validate() error     { return nil }
+
+func (r *priceRule) Evaluate(ctx context.Context, ruleCtx *RuleContext) (*RuleResult, error) {
+	return &RuleResult{Triggered: ruleCtx.Stock.Price > r.threshold, RuleName: r.Name(), Level: model.AlertLevelInfo}, nil
+}
+
+func TestEvaluateStream(t *testing.T) {
+	e := NewEngine()
+	e.AddRule(&priceRule{threshold: 100})
+
+	quotes := make(chan *model.Stock, 4)
+	quotes <- &model.Stock{Code: "600519", Price: 99}
+	quotes <- &model.Stock{Code: "600519", Price: 101}
+	quotes <- &model.Stock{Code: "000001", Price: 200} // build 返回 nil，跳过
+	quotes <- &model.Stock{Code: "600519", Price: 102}
+	close(quotes)
+
+	build := func(ctx context.Context, st *model.Stock) *RuleContext {
+		if st.Code == "000001" {
+			return nil
+		}
+		return &RuleContext{Stock: st}
+	}
+	var prices []float64
+	e.EvaluateStream(context.Background(), quotes, build, func(alerts []*model.Alert) {
+		for _, a := range alerts {
+			prices = append(prices, a.Price)
+		}
+	})
+
+	if len(prices) != 2 || prices[0] != 101 || prices[1] != 102 {
+		t.Errorf("alert prices = %v, want [101 102]", prices)
+	}
+}
+
+func TestEvaluateStreamCancel(t *testing.T) {
+	e := NewEngine()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// 通道未关闭，ctx 取消后应立即返回
+	e.EvaluateStream(ctx, make(chan *model.Stock), nil, func([]*model.Alert) {})
+}
+
+// alwaysRule 总是触发，用于验证路由
+type alwaysRule struct{ name string }
+
+func (r *alwaysRule) Name() string        { return r.name }
+func (r *alwaysRule) Description() string { return r.name }
+func (r *alwaysRule) Validate() error     { return nil }
+
+func (r *alwaysRule) Evaluate(ctx context.Context, ruleCtx *RuleContext) (*RuleResult, error) {
+	return &RuleResult{Triggered: true, RuleName: r.name, Level: model.AlertLevelInfo}, nil
+}
+
+type marketRule struct{ alwaysRule }
+
+func (r *marketRule) MarketWide() {}
+
+type groupRule struct{ alwaysRule }
+
+func (r *groupRule) Board() string              { return "BK0475" }
+func (r *groupRule) Members() []string          { return nil }
+func (r *groupRule) KLineType() model.KLineType { return "" }
+
+func TestEngineRouting(t *testing.T) {
+	e := NewEngine()
+	e.AddRule(&alwaysRule{name: "stock"})
+	e.AddRule(&marketRule{alwaysRule{name: "market"}})
+	e.AddRule(&groupRule{alwaysRule{name: "group"}})
+
+	stock := &model.Stock{Code: "600519", Name: "贵州茅台", Price: 1700}
+	group := &model.Group{Code: "BK0475", Name: "银行", Board: &model.Board{Price: 3200}}
+	market := &model.MarketOverview{Advancing: 1}
+
+	tests := []struct {
+		name    string
+		ctx     *RuleContext
+		want    string // 触发的规则
+		code    string
+		channel string
+	}{
+		{"个股", &RuleContext{Stock: stock}, "stock", "600519", ""},
+		{"个股附带市场概况", &RuleContext{Stock: stock, Market: market}, "stock", "600519", ""},
+		{"分组", &RuleContext{Group: group}, "group", "BK0475", ""},
+		{"市场", &RuleContext{Market: market}, "market", "", model.ChannelMarket},
+	}
+	for _, tt := range tests {
+		alerts, err := e.Evaluate(context.Background(), tt.ctx)
+		if err != nil || len(alerts) != 1 {
+			t.Errorf("%s: alerts = %v, err = %v", tt.name, alerts, err)
+			continue
+		}
+		a := alerts[0]
+		if a.RuleName != tt.want || a.StockCode != tt.code || a.Channel != tt.channel {
+			t.Errorf("%s: alert = %s code=%q channel=%q", tt.name, a.RuleName, a.StockCode, a.Channel)
+		}
+	}
+
+	if rules := e.GroupRules(); len(rules) != 1 || rules[0].Board() != "BK0475" {
+		t.Errorf("GroupRules = %v", rules)
+	}
+}
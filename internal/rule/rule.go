@@ -8,8 +8,12 @@ import (
 
 // RuleContext 规则执行上下文
 type RuleContext struct {
-	Stock  *model.Stock
-	KLines *model.KLineData
+	Stock        *model.Stock
+	KLines       *model.KLineData
+	Benchmark    *model.KLineData      // 基准指数K线，仅 BenchmarkRule 需要
+	Group        *model.Group          // 板块/自选分组数据，仅 GroupRule 需要，此时 Stock 为 nil
+	Market       *model.MarketOverview // 市场概况，仅 MarketRule 需要，此时 Stock 为 nil
+	Fundamentals *model.Fundamentals   // 基本面数据，未配置基本面数据来源或获取失败时为 nil
 }
 
 // RuleResult 规则执行结果
@@ -35,3 +39,27 @@ type KLineRule interface {
 	KLineType() model.KLineType
 	StockCode() string
 }
+
+// BenchmarkRule 需要基准指数K线的规则接口，基准K线与个股K线周期相同
+type BenchmarkRule interface {
+	KLineRule
+	BenchmarkCode() string // 带交易所前缀的指数代码，如 sh000300
+}
+
+// GroupRule 对板块或一组股票整体评估的规则
+//
+// 调用方按 Board、Members 获取分组数据后以 RuleContext.Group 传入，Stock 为 nil。
+type GroupRule interface {
+	Rule
+	Board() string              // 板块代码或名称，为空时只使用 Members
+	Members() []string          // 自选股票代码
+	KLineType() model.KLineType // 需要成员K线的周期，不需要时为空
+}
+
+// MarketRule 对市场整体评估的规则，告警发送到 model.ChannelMarket 频道
+//
+// 调用方以 RuleContext.Market 传入市场概况，Stock 为 nil。
+type MarketRule interface {
+	Rule
+	MarketWide() // 标记方法
+}
@@ -0,0 +1,120 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+)
+
+func init() {
+	rule.GlobalRegistry.Register("board_change", NewBoardChangeRule, "板块涨跌幅")
+}
+
+// BoardChangeRule 板块涨跌幅规则：板块指数（无指数时为成员平均）涨跌幅超过阈值时触发，
+// 告警列出涨跌幅最大的成员
+type BoardChangeRule struct {
+	name      string
+	board     string
+	members   []string
+	threshold float64 // 涨跌幅阈值 %
+	direction string  // up / down / both
+	level     model.AlertLevel
+}
+
+// NewBoardChangeRule 创建规则
+//
+// 参数 board 为板块名称或代码（如 半导体、BK1036），也可用 members 指定一组自选股票。
+func NewBoardChangeRule(name string, level model.AlertLevel, params map[string]interface{}) (rule.Rule, error) {
+	return &BoardChangeRule{
+		name:      name,
+		board:     stringParam(params, "board", ""),
+		members:   stringListParam(params, "members"),
+		threshold: floatParam(params, "threshold", 3),
+		direction: stringParam(params, "direction", "up"),
+		level:     level,
+	}, nil
+}
+
+func (r *BoardChangeRule) Name() string               { return r.name }
+func (r *BoardChangeRule) Board() string              { return r.board }
+func (r *BoardChangeRule) Members() []string          { return r.members }
+func (r *BoardChangeRule) KLineType() model.KLineType { return "" }
+
+func (r *BoardChangeRule) Description() string {
+	target := r.board
+	if target == "" {
+		target = fmt.Sprintf("%d只自选股", len(r.members))
+	}
+	switch r.direction {
+	case "down":
+		return fmt.Sprintf("%s 跌幅超过 %.2f%%", target, r.threshold)
+	case "both":
+		return fmt.Sprintf("%s 涨跌幅超过 %.2f%%", target, r.threshold)
+	}
+	return fmt.Sprintf("%s 涨幅超过 %.2f%%", target, r.threshold)
+}
+
+func (r *BoardChangeRule) Validate() error {
+	if r.board == "" && len(r.members) == 0 {
+		return fmt.Errorf("board or members is required")
+	}
+	if r.threshold <= 0 {
+		return fmt.Errorf("threshold must be positive")
+	}
+	switch r.direction {
+	case "up", "down", "both":
+	default:
+		return fmt.Errorf("unknown direction: %s", r.direction)
+	}
+	return nil
+}
+
+func (r *BoardChangeRule) Evaluate(ctx context.Context, ruleCtx *rule.RuleContext) (*rule.RuleResult, error) {
+	group := ruleCtx.Group
+	if group == nil || len(group.Members) == 0 && group.Board == nil {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	change := group.ChangePercent()
+	if math.Abs(change) <= r.threshold ||
+		(r.direction == "up" && change < 0) || (r.direction == "down" && change > 0) {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	// 与板块同向的成员按涨跌幅排序，列出贡献最大的几只
+	var movers []*model.Stock
+	for _, st := range group.Members {
+		if st.ChangePercent()*change > 0 {
+			movers = append(movers, st)
+		}
+	}
+	sort.SliceStable(movers, func(i, j int) bool {
+		return math.Abs(movers[i].ChangePercent()) > math.Abs(movers[j].ChangePercent())
+	})
+
+	verb := "涨"
+	if change < 0 {
+		verb = "跌"
+	}
+	message := fmt.Sprintf("%s 板块%s %.2f%%", group.Name, verb, math.Abs(change))
+	if len(movers) > 0 {
+		message += fmt.Sprintf("，%d/%d 只成员同向，领%s: %s",
+			len(movers), len(group.Members), verb, memberList(movers, groupMemberLimit))
+	}
+
+	return &rule.RuleResult{
+		Triggered: true,
+		RuleName:  r.name,
+		Level:     r.level,
+		Message:   message,
+		Extra: map[string]interface{}{
+			"change_percent": change,
+			"members":        memberCodes(movers),
+			"member_count":   len(group.Members),
+		},
+	}, nil
+}
@@ -0,0 +1,133 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/pattern"
+	"stock-monitor/internal/rule"
+)
+
+func init() {
+	rule.GlobalRegistry.Register("candle_pattern", NewCandlePatternRule, "K线形态")
+}
+
+// CandlePatternRule K线形态规则：最后一根已完成K线构成所选形态时触发
+type CandlePatternRule struct {
+	name      string
+	patterns  []pattern.Type
+	opts      pattern.Options
+	stockCode string
+	klineType model.KLineType
+	level     model.AlertLevel
+}
+
+// NewCandlePatternRule 创建规则
+//
+// 参数 patterns 为形态列表（数组或逗号分隔），为空表示全部形态；
+// doji_body_ratio / small_body_ratio / long_body_ratio / long_shadow_ratio /
+// short_shadow_ratio / trend_bars 可覆盖默认识别阈值。
+func NewCandlePatternRule(name string, level model.AlertLevel, params map[string]interface{}) (rule.Rule, error) {
+	var patterns []pattern.Type
+	for _, p := range stringListParam(params, "patterns") {
+		patterns = append(patterns, pattern.Type(p))
+	}
+	if len(patterns) == 0 {
+		patterns = pattern.AllTypes
+	}
+
+	opts := pattern.DefaultOptions()
+	opts.DojiBodyRatio = floatParam(params, "doji_body_ratio", opts.DojiBodyRatio)
+	opts.SmallBodyRatio = floatParam(params, "small_body_ratio", opts.SmallBodyRatio)
+	opts.LongBodyRatio = floatParam(params, "long_body_ratio", opts.LongBodyRatio)
+	opts.LongShadowRatio = floatParam(params, "long_shadow_ratio", opts.LongShadowRatio)
+	opts.ShortShadowRatio = floatParam(params, "short_shadow_ratio", opts.ShortShadowRatio)
+	opts.TrendBars = intParam(params, "trend_bars", opts.TrendBars)
+
+	stockCode, _ := params["stock_code"].(string)
+
+	return &CandlePatternRule{
+		name:      name,
+		patterns:  patterns,
+		opts:      opts,
+		stockCode: stockCode,
+		klineType: klineTypeParam(params),
+		level:     level,
+	}, nil
+}
+
+func (r *CandlePatternRule) Name() string               { return r.name }
+func (r *CandlePatternRule) StockCode() string          { return r.stockCode }
+func (r *CandlePatternRule) KLineType() model.KLineType { return r.klineType }
+
+func (r *CandlePatternRule) Description() string {
+	names := make([]string, len(r.patterns))
+	for i, p := range r.patterns {
+		names[i] = p.DisplayName()
+	}
+	return fmt.Sprintf("%s K线形态: %s", r.klineType, strings.Join(names, "/"))
+}
+
+func (r *CandlePatternRule) Validate() error {
+	for _, p := range r.patterns {
+		if !p.Valid() {
+			return fmt.Errorf("unknown pattern: %s", p)
+		}
+	}
+	if r.opts.DojiBodyRatio < 0 || r.opts.SmallBodyRatio <= 0 || r.opts.LongBodyRatio <= 0 ||
+		r.opts.LongShadowRatio <= 0 || r.opts.ShortShadowRatio < 0 {
+		return fmt.Errorf("pattern ratios must be positive")
+	}
+	if r.opts.TrendBars < 0 {
+		return fmt.Errorf("trend_bars must not be negative")
+	}
+	return nil
+}
+
+func (r *CandlePatternRule) Evaluate(ctx context.Context, ruleCtx *rule.RuleContext) (*rule.RuleResult, error) {
+	if r.stockCode != "" && ruleCtx.Stock.Code != r.stockCode {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	if ruleCtx.KLines == nil {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	lines := ruleCtx.KLines.Lines
+	last := lastCompletedIndex(ruleCtx.KLines, ruleCtx.Stock.Time)
+	if last < 0 {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	selected := make(map[pattern.Type]bool, len(r.patterns))
+	for _, p := range r.patterns {
+		selected[p] = true
+	}
+
+	for _, m := range pattern.Detect(lines, last, r.opts) {
+		if !selected[m.Type] {
+			continue
+		}
+		bars := make([]string, 0, m.End-m.Start+1)
+		for _, k := range lines[m.Start : m.End+1] {
+			bars = append(bars, k.Time.Format("2006-01-02 15:04"))
+		}
+		return &rule.RuleResult{
+			Triggered: true,
+			RuleName:  r.name,
+			Level:     r.level,
+			Message: fmt.Sprintf("%s %s K线出现%s形态 (%s)",
+				ruleCtx.Stock.Name, r.klineType, m.Type.DisplayName(), bars[len(bars)-1]),
+			Extra: map[string]interface{}{
+				"pattern":      string(m.Type),
+				"pattern_name": m.Type.DisplayName(),
+				"bars":         bars,
+				"bar_start":    lines[m.Start].Time,
+				"bar_end":      lines[m.End].Time,
+			},
+		}, nil
+	}
+
+	return &rule.RuleResult{Triggered: false}, nil
+}
@@ -0,0 +1,85 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+)
+
+func init() {
+	rule.GlobalRegistry.Register("convertible_premium", NewConvertiblePremiumRule, "转债溢价率")
+}
+
+// ConvertiblePremiumRule 可转债转股溢价率规则：溢价率低于（或高于）阈值时触发
+//
+// 需要数据源配置 bond_terms 提供转股价，否则行情中没有溢价率数据。
+type ConvertiblePremiumRule struct {
+	name      string
+	threshold float64 // 溢价率阈值 %
+	direction string  // below / above
+	stockCode string
+	level     model.AlertLevel
+}
+
+// NewConvertiblePremiumRule 创建规则
+func NewConvertiblePremiumRule(name string, level model.AlertLevel, params map[string]interface{}) (rule.Rule, error) {
+	stockCode, _ := params["stock_code"].(string)
+
+	return &ConvertiblePremiumRule{
+		name:      name,
+		threshold: floatParam(params, "threshold", 10),
+		direction: stringParam(params, "direction", "below"),
+		stockCode: stockCode,
+		level:     level,
+	}, nil
+}
+
+func (r *ConvertiblePremiumRule) Name() string { return r.name }
+
+func (r *ConvertiblePremiumRule) Description() string {
+	if r.direction == "above" {
+		return fmt.Sprintf("转股溢价率高于 %.2f%%", r.threshold)
+	}
+	return fmt.Sprintf("转股溢价率低于 %.2f%%", r.threshold)
+}
+
+func (r *ConvertiblePremiumRule) Validate() error {
+	switch r.direction {
+	case "below", "above":
+	default:
+		return fmt.Errorf("unknown direction: %s", r.direction)
+	}
+	return nil
+}
+
+func (r *ConvertiblePremiumRule) Evaluate(ctx context.Context, ruleCtx *rule.RuleContext) (*rule.RuleResult, error) {
+	stock := ruleCtx.Stock
+	if r.stockCode != "" && stock.Code != r.stockCode {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	bond := stock.Bond
+	if bond == nil || bond.ConversionValue <= 0 {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	if (r.direction == "below" && bond.Premium >= r.threshold) ||
+		(r.direction == "above" && bond.Premium <= r.threshold) {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	return &rule.RuleResult{
+		Triggered: true,
+		RuleName:  r.name,
+		Level:     r.level,
+		Message: fmt.Sprintf("%s 转股溢价率 %.2f%% (现价 %.3f，转股价值 %.2f，正股 %s %.2f)",
+			stock.Name, bond.Premium, stock.Price, bond.ConversionValue, bond.UnderlyingName, bond.UnderlyingPrice),
+		Extra: map[string]interface{}{
+			"premium":          bond.Premium,
+			"conversion_value": bond.ConversionValue,
+			"conversion_price": bond.ConversionPrice,
+			"underlying_code":  bond.UnderlyingCode,
+			"underlying_price": bond.UnderlyingPrice,
+		},
+	}, nil
+}
@@ -0,0 +1,182 @@
+package rules
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+)
+
+// levels 由 (价格, 股数) 依次构造五档盘口的一侧
+func levels(pairs ...float64) [model.DepthLevels]model.DepthLevel {
+	var out [model.DepthLevels]model.DepthLevel
+	for i := 0; i+1 < len(pairs) && i/2 < model.DepthLevels; i += 2 {
+		out[i/2] = model.DepthLevel{Price: pairs[i], Volume: int64(pairs[i+1])}
+	}
+	return out
+}
+
+func mustRule(t *testing.T, factory rule.RuleFactory, params map[string]interface{}) rule.Rule {
+	t.Helper()
+	r, err := factory("test", model.AlertLevelWarning, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestOrderImbalanceRule(t *testing.T) {
+	bidHeavy := &model.Depth{
+		Bids: levels(9.99, 10000, 9.98, 10000, 9.97, 10000, 9.96, 10000, 9.95, 10000),
+		Asks: levels(10.00, 2000, 10.01, 2000, 10.02, 2000, 10.03, 2000, 10.04, 2000),
+	}
+	stock := &model.Stock{Code: "000001", Name: "平安银行", Price: 10, Depth: bidHeavy}
+
+	r := mustRule(t, NewOrderImbalanceRule, map[string]interface{}{})
+	result, err := r.Evaluate(context.Background(), &rule.RuleContext{Stock: stock})
+	if err != nil || !result.Triggered {
+		t.Fatalf("Evaluate = %+v, %v", result, err)
+	}
+	// (50000-10000)/(50000+10000)
+	if got := result.Extra["imbalance"].(float64); got < 0.666 || got > 0.667 {
+		t.Errorf("imbalance = %v, want 0.667", got)
+	}
+	if !strings.Contains(result.Message, "买盘占优") || !strings.Contains(result.Message, "买 500 手 / 卖 100 手") {
+		t.Errorf("message = %q", result.Message)
+	}
+
+	for _, params := range []map[string]interface{}{
+		{"side": "ask"},
+		{"threshold": 0.7},
+	} {
+		r := mustRule(t, NewOrderImbalanceRule, params)
+		if result, _ := r.Evaluate(context.Background(), &rule.RuleContext{Stock: stock}); result.Triggered {
+			t.Errorf("%v: triggered, want not", params)
+		}
+	}
+	if result, _ := r.Evaluate(context.Background(), &rule.RuleContext{Stock: &model.Stock{Code: "000001"}}); result.Triggered {
+		t.Error("quote without depth should not trigger")
+	}
+}
+
+func TestOrderWallRule(t *testing.T) {
+	normal := &model.Depth{
+		Bids: levels(9.99, 10000, 9.98, 10000, 9.97, 10000, 9.96, 10000, 9.95, 10000),
+		Asks: levels(10.00, 10000, 10.01, 10000, 10.02, 10000, 10.03, 10000, 10.04, 10000),
+	}
+	wall := &model.Depth{
+		Bids: levels(9.99, 10000, 9.98, 10000, 9.97, 1000000, 9.96, 10000, 9.95, 10000),
+		Asks: normal.Asks,
+	}
+	r := mustRule(t, NewOrderWallRule, map[string]interface{}{})
+
+	// 同一价位的大单只在出现时触发一次，消失后再次出现重新触发
+	steps := []struct {
+		code  string
+		depth *model.Depth
+		want  bool
+	}{
+		{"000001", normal, false},
+		{"000001", wall, true},
+		{"000001", wall, false},
+		{"600036", wall, true}, // 状态按代码区分
+		{"000001", normal, false},
+		{"000001", wall, true},
+	}
+	for i, s := range steps {
+		stock := &model.Stock{Code: s.code, Name: s.code, Price: 9.99, Depth: s.depth}
+		result, err := r.Evaluate(context.Background(), &rule.RuleContext{Stock: stock})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Triggered != s.want {
+			t.Fatalf("step %d (%s): triggered = %v, want %v", i, s.code, result.Triggered, s.want)
+		}
+		if !result.Triggered {
+			continue
+		}
+		if result.Extra["side"] != "bid" || result.Extra["level"] != 3 || result.Extra["price"] != 9.97 {
+			t.Errorf("step %d: extra = %v", i, result.Extra)
+		}
+		if !strings.Contains(result.Message, "买3 档 9.97 出现大单 10000 手") {
+			t.Errorf("step %d: message = %q", i, result.Message)
+		}
+	}
+
+	// 只看卖盘时忽略买盘大单
+	askOnly := mustRule(t, NewOrderWallRule, map[string]interface{}{"side": "ask"})
+	if result, _ := askOnly.Evaluate(context.Background(), &rule.RuleContext{Stock: &model.Stock{Code: "000001", Depth: wall}}); result.Triggered {
+		t.Error("ask-only rule triggered on a bid wall")
+	}
+}
+
+func TestLimitUpSealRule(t *testing.T) {
+	quote := func(price, bid1 float64, sealVolume int64) *model.Stock {
+		return &model.Stock{
+			Code: "000001", Name: "平安银行", Price: price, PreClose: 10, LimitUp: 11,
+			Depth: &model.Depth{Bids: levels(bid1, float64(sealVolume))},
+		}
+	}
+	r := mustRule(t, NewLimitUpSealRule, map[string]interface{}{"min_amount": 1e8})
+
+	steps := []struct {
+		name   string
+		stock  *model.Stock
+		want   bool
+		opened bool
+	}{
+		{"封单不足但此前未封板", quote(11, 11, 5000000), false, false},
+		{"封板 2.2 亿", quote(11, 11, 20000000), false, false},
+		{"封单降至 5500 万", quote(11, 11, 5000000), true, false},
+		{"封单仍不足", quote(11, 11, 4000000), false, false},
+		{"重新封板", quote(11, 11, 20000000), false, false},
+		{"开板", quote(10.9, 10.89, 300000), true, true},
+		{"仍未回封", quote(10.85, 10.84, 300000), false, false},
+	}
+	for _, s := range steps {
+		result, err := r.Evaluate(context.Background(), &rule.RuleContext{Stock: s.stock})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Triggered != s.want {
+			t.Fatalf("%s: triggered = %v, want %v", s.name, result.Triggered, s.want)
+		}
+		if !result.Triggered {
+			continue
+		}
+		if result.Extra["opened"] != s.opened || result.Extra["limit_up"] != 11.0 {
+			t.Errorf("%s: extra = %v", s.name, result.Extra)
+		}
+		want := "涨停封单降至 50000 手 (5500 万元)"
+		if s.opened {
+			want = "涨停板打开，现价 10.90"
+		}
+		if !strings.Contains(result.Message, want) {
+			t.Errorf("%s: message = %q, want %q", s.name, result.Message, want)
+		}
+	}
+}
+
+func TestLimitUpPrice(t *testing.T) {
+	tests := []struct {
+		stock       *model.Stock
+		price, tick float64
+	}{
+		{&model.Stock{Code: "000001", LimitUp: 11.01}, 11.01, 0.01},
+		{&model.Stock{Code: "600519", Name: "贵州茅台", PreClose: 1685.32}, 1853.85, 0.01},
+		{&model.Stock{Code: "300750", Name: "宁德时代", PreClose: 200}, 240, 0.01},
+		{&model.Stock{Code: "510300", Name: "沪深300ETF", PreClose: 3.857}, 4.243, 0.001},
+		{&model.Stock{Code: "sh000001", Name: "上证指数", PreClose: 3000}, 0, 0.01},
+	}
+	for _, tt := range tests {
+		price, tick := limitUpPrice(tt.stock)
+		if price != tt.price || tick != tt.tick {
+			t.Errorf("limitUpPrice(%s) = %v/%v, want %v/%v", tt.stock.Code, price, tick, tt.price, tt.tick)
+		}
+	}
+}
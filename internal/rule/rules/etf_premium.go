@@ -0,0 +1,94 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+)
+
+func init() {
+	rule.GlobalRegistry.Register("etf_premium", NewETFPremiumRule, "ETF溢价")
+}
+
+// ETFPremiumRule ETF溢价率规则：相对参考净值溢价（或折价）超过阈值时触发
+//
+// 需要数据源开启 etf_iopv，否则行情中没有参考净值。
+type ETFPremiumRule struct {
+	name      string
+	threshold float64 // 溢价率阈值 %
+	direction string  // above 溢价 / below 折价 / both
+	stockCode string
+	level     model.AlertLevel
+}
+
+// NewETFPremiumRule 创建规则
+func NewETFPremiumRule(name string, level model.AlertLevel, params map[string]interface{}) (rule.Rule, error) {
+	stockCode, _ := params["stock_code"].(string)
+
+	return &ETFPremiumRule{
+		name:      name,
+		threshold: floatParam(params, "threshold", 1),
+		direction: stringParam(params, "direction", "above"),
+		stockCode: stockCode,
+		level:     level,
+	}, nil
+}
+
+func (r *ETFPremiumRule) Name() string { return r.name }
+
+func (r *ETFPremiumRule) Description() string {
+	switch r.direction {
+	case "below":
+		return fmt.Sprintf("ETF折价超过 %.2f%%", r.threshold)
+	case "both":
+		return fmt.Sprintf("ETF溢价或折价超过 %.2f%%", r.threshold)
+	}
+	return fmt.Sprintf("ETF溢价超过 %.2f%%", r.threshold)
+}
+
+func (r *ETFPremiumRule) Validate() error {
+	if r.threshold <= 0 {
+		return fmt.Errorf("threshold must be positive")
+	}
+	switch r.direction {
+	case "above", "below", "both":
+	default:
+		return fmt.Errorf("unknown direction: %s", r.direction)
+	}
+	return nil
+}
+
+func (r *ETFPremiumRule) Evaluate(ctx context.Context, ruleCtx *rule.RuleContext) (*rule.RuleResult, error) {
+	stock := ruleCtx.Stock
+	if r.stockCode != "" && stock.Code != r.stockCode {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	etf := stock.ETF
+	if etf == nil || etf.IOPV <= 0 {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	premium := etf.Premium
+	if math.Abs(premium) <= r.threshold ||
+		(r.direction == "above" && premium < 0) || (r.direction == "below" && premium > 0) {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	kind := "溢价"
+	if premium < 0 {
+		kind = "折价"
+	}
+	return &rule.RuleResult{
+		Triggered: true,
+		RuleName:  r.name,
+		Level:     r.level,
+		Message: fmt.Sprintf("%s %s %.2f%% (现价 %.3f，参考净值 %.4f)",
+			stock.Name, kind, math.Abs(premium), stock.Price, etf.IOPV),
+		Extra: map[string]interface{}{
+			"premium": premium,
+			"iopv":    etf.IOPV,
+		},
+	}, nil
+}
@@ -0,0 +1,17 @@
+package rules
+
+import (
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+)
+
+// fundamentalsOf 基本面数据：优先使用上下文中的数据，未提供时取行情自带的字段（如腾讯数据源）
+func fundamentalsOf(ruleCtx *rule.RuleContext) *model.Fundamentals {
+	if ruleCtx.Fundamentals != nil {
+		return ruleCtx.Fundamentals
+	}
+	if ruleCtx.Stock == nil {
+		return nil
+	}
+	return model.FundamentalsOf(ruleCtx.Stock)
+}
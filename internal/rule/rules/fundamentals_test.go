@@ -0,0 +1,87 @@
+package rules
+
+import (
+	"testing"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+)
+
+func TestValueAboveMARule(t *testing.T) {
+	klines := &model.KLineData{Code: "601318", Type: model.KLineDaily}
+	for i := 0; i < 5; i++ {
+		klines.Lines = append(klines.Lines, model.KLine{Open: 40, High: 40, Low: 40, Close: 40})
+	}
+	ctx := func(price float64, f *model.Fundamentals) *rule.RuleContext {
+		return &rule.RuleContext{
+			Stock:        &model.Stock{Code: "601318", Name: "中国平安", Price: price},
+			KLines:       klines,
+			Fundamentals: f,
+		}
+	}
+	params := map[string]interface{}{"period": 5, "pe_max": 10}
+	const cheap = "中国平安 PE 8.0 PB 0.90，现价 42.00 站上 MA5 (40.00)"
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		ruleCtx *rule.RuleContext
+		message string // 不触发时为空
+	}{
+		{"低估值站上均线", params, ctx(42, &model.Fundamentals{PE: 8, PB: 0.9}), cheap},
+		{"低于均线", params, ctx(38, &model.Fundamentals{PE: 8, PB: 0.9}), ""},
+		{"亏损股不触发", params, ctx(42, &model.Fundamentals{PE: -5, PB: 0.9}), ""},
+		{"市盈率未知", params, ctx(42, &model.Fundamentals{PB: 0.9}), ""},
+		{"市盈率过高", params, ctx(42, &model.Fundamentals{PE: 12, PB: 0.9}), ""},
+		{"市净率符合", map[string]interface{}{"period": 5, "pe_max": 10, "pb_max": 1}, ctx(42, &model.Fundamentals{PE: 8, PB: 0.9}), cheap},
+		{"市净率过高", map[string]interface{}{"period": 5, "pe_max": 10, "pb_max": 0.8}, ctx(42, &model.Fundamentals{PE: 8, PB: 0.9}), ""},
+		{"K线不足周期", map[string]interface{}{"period": 10, "pe_max": 10}, ctx(42, &model.Fundamentals{PE: 8}), ""},
+		{"没有基本面数据", params, ctx(42, nil), ""},
+		{"使用行情中的市盈率", params, &rule.RuleContext{
+			Stock:  &model.Stock{Code: "601318", Name: "中国平安", Price: 42, PE: 8},
+			KLines: klines,
+		}, "中国平安 PE 8.0 PB 0.00，现价 42.00 站上 MA5 (40.00)"},
+	}
+	for _, tt := range tests {
+		result := evaluate(t, NewValueAboveMARule, tt.params, tt.ruleCtx)
+		if result.Triggered != (tt.message != "") || result.Message != tt.message {
+			t.Errorf("%s: got (%v, %q), want %q", tt.name, result.Triggered, result.Message, tt.message)
+		}
+		if result.Triggered && result.Extra["ma_value"] != 40.0 {
+			t.Errorf("%s: ma_value = %v, want 40", tt.name, result.Extra["ma_value"])
+		}
+	}
+}
+
+func TestTurnoverRateRule(t *testing.T) {
+	ctx := func(f *model.Fundamentals) *rule.RuleContext {
+		return &rule.RuleContext{Stock: &model.Stock{Code: "300750", Name: "宁德时代", Price: 200, PreClose: 190}, Fundamentals: f}
+	}
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		ruleCtx *rule.RuleContext
+		rate    float64 // 告警中的换手率，0 表示不触发
+	}{
+		{"换手率超过10%", map[string]interface{}{}, ctx(&model.Fundamentals{TurnoverRate: 12.5}), 12.5},
+		{"换手率未超过阈值", map[string]interface{}{}, ctx(&model.Fundamentals{TurnoverRate: 8}), 0},
+		{"换手率低于1%", map[string]interface{}{"op": "below", "threshold": 1}, ctx(&model.Fundamentals{TurnoverRate: 0.6}), 0.6},
+		{"换手率未低于阈值", map[string]interface{}{"op": "below", "threshold": 1}, ctx(&model.Fundamentals{TurnoverRate: 1.2}), 0},
+		{"换手率未知", map[string]interface{}{"op": "below", "threshold": 1}, ctx(&model.Fundamentals{PE: 20}), 0},
+		{"使用行情中的换手率", map[string]interface{}{}, &rule.RuleContext{
+			Stock: &model.Stock{Code: "300750", Name: "宁德时代", Price: 200, TurnoverRate: 15},
+		}, 15},
+		{"其他代码", map[string]interface{}{"stock_code": "600519"}, ctx(&model.Fundamentals{TurnoverRate: 12.5}), 0},
+	}
+	for _, tt := range tests {
+		result := evaluate(t, NewTurnoverRateRule, tt.params, tt.ruleCtx)
+		got, _ := result.Extra["turnover_rate"].(float64)
+		if result.Triggered != (tt.rate != 0) || got != tt.rate {
+			t.Errorf("%s: triggered = %v, turnover_rate = %v, want %v", tt.name, result.Triggered, got, tt.rate)
+		}
+	}
+
+	result := evaluate(t, NewTurnoverRateRule, map[string]interface{}{}, ctx(&model.Fundamentals{TurnoverRate: 12.5, FloatMarketCap: 8000e8}))
+	if want := "宁德时代 换手率 12.50% (流通市值 8000 亿，涨跌幅 +5.26%)"; result.Message != want {
+		t.Errorf("message = %q, want %q", result.Message, want)
+	}
+}
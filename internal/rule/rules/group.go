@@ -0,0 +1,40 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"stock-monitor/internal/model"
+)
+
+// groupMemberLimit 告警消息中最多列出的成员数
+const groupMemberLimit = 5
+
+// memberList 列出成员名称及涨跌幅，超过 limit 只列前 limit 只
+func memberList(stocks []*model.Stock, limit int) string {
+	parts := make([]string, 0, min(len(stocks), limit))
+	for i, st := range stocks {
+		if i == limit {
+			break
+		}
+		name := st.Name
+		if name == "" {
+			name = st.Code
+		}
+		parts = append(parts, fmt.Sprintf("%s %+.2f%%", name, st.ChangePercent()))
+	}
+	s := strings.Join(parts, "、")
+	if len(stocks) > limit {
+		s += fmt.Sprintf(" 等%d只", len(stocks))
+	}
+	return s
+}
+
+// memberCodes 成员代码列表，用于告警 Extra
+func memberCodes(stocks []*model.Stock) []string {
+	codes := make([]string, len(stocks))
+	for i, st := range stocks {
+		codes[i] = st.Code
+	}
+	return codes
+}
@@ -0,0 +1,112 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+
+	"stock-monitor/internal/indicator"
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+)
+
+func init() {
+	rule.GlobalRegistry.Register("group_below_ma", NewGroupBelowMARule, "分组破均线")
+}
+
+// GroupBelowMARule 分组破均线规则：一组股票中现价低于均线的比例超过阈值时触发，
+// 告警列出低于均线的成员
+type GroupBelowMARule struct {
+	name      string
+	board     string
+	members   []string
+	period    int
+	ratio     float64 // 比例阈值 %
+	klineType model.KLineType
+	level     model.AlertLevel
+}
+
+// NewGroupBelowMARule 创建规则
+//
+// 参数 members 为自选股票代码，也可用 board 指定板块；均线按已完成K线的收盘价计算。
+func NewGroupBelowMARule(name string, level model.AlertLevel, params map[string]interface{}) (rule.Rule, error) {
+	return &GroupBelowMARule{
+		name:      name,
+		board:     stringParam(params, "board", ""),
+		members:   stringListParam(params, "members"),
+		period:    intParam(params, "period", 20),
+		ratio:     floatParam(params, "ratio", 60),
+		klineType: klineTypeParam(params),
+		level:     level,
+	}, nil
+}
+
+func (r *GroupBelowMARule) Name() string               { return r.name }
+func (r *GroupBelowMARule) Board() string              { return r.board }
+func (r *GroupBelowMARule) Members() []string          { return r.members }
+func (r *GroupBelowMARule) KLineType() model.KLineType { return r.klineType }
+
+func (r *GroupBelowMARule) Description() string {
+	return fmt.Sprintf("超过 %.0f%% 的成员跌破 %s MA%d", r.ratio, r.klineType, r.period)
+}
+
+func (r *GroupBelowMARule) Validate() error {
+	if r.board == "" && len(r.members) == 0 {
+		return fmt.Errorf("board or members is required")
+	}
+	if r.period <= 0 {
+		return fmt.Errorf("period must be positive")
+	}
+	if r.ratio <= 0 || r.ratio > 100 {
+		return fmt.Errorf("ratio must be between 0 and 100")
+	}
+	return nil
+}
+
+func (r *GroupBelowMARule) Evaluate(ctx context.Context, ruleCtx *rule.RuleContext) (*rule.RuleResult, error) {
+	group := ruleCtx.Group
+	if group == nil || len(group.KLines) == 0 {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	var below []*model.Stock
+	counted := 0
+	for _, st := range group.Members {
+		data := group.KLines[st.Code]
+		if data == nil {
+			continue
+		}
+		last := lastCompletedIndex(data, st.Time)
+		if last+1 < r.period {
+			continue
+		}
+		closes := make([]float64, last+1)
+		for i := range closes {
+			closes[i] = data.Lines[i].Close
+		}
+		counted++
+		if st.Price < indicator.LastMA(closes, r.period) {
+			below = append(below, st)
+		}
+	}
+	if counted == 0 {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	pct := float64(len(below)) / float64(counted) * 100
+	if pct <= r.ratio {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	return &rule.RuleResult{
+		Triggered: true,
+		RuleName:  r.name,
+		Level:     r.level,
+		Message: fmt.Sprintf("%s %d/%d 只 (%.0f%%) 跌破 MA%d: %s",
+			group.Name, len(below), counted, pct, r.period, memberList(below, groupMemberLimit)),
+		Extra: map[string]interface{}{
+			"ratio":   pct,
+			"members": memberCodes(below),
+			"period":  r.period,
+		},
+	}, nil
+}
@@ -0,0 +1,136 @@
+package rules
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+)
+
+func member(code string, price, preClose float64) *model.Stock {
+	return &model.Stock{Code: code, Name: code, Price: price, PreClose: preClose}
+}
+
+func TestBoardChangeRule(t *testing.T) {
+	board := &model.Group{
+		Code:  "BK1036",
+		Name:  "半导体",
+		Board: &model.Board{Code: "BK1036", Name: "半导体", Price: 1200, ChangePercent: 3.5},
+		Members: []*model.Stock{
+			member("688981", 104, 100), member("603501", 101, 100), member("002371", 99, 100),
+		},
+	}
+	custom := &model.Group{Name: "自选", Members: []*model.Stock{
+		member("600519", 96, 100), member("000858", 97, 100), // 平均 -3.5%
+	}}
+	// members 为告警中列出的同向成员，nil 表示不触发
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		group   *model.Group
+		members []string
+	}{
+		{"板块指数大涨", map[string]interface{}{"board": "半导体"}, board, []string{"688981", "603501"}},
+		{"涨幅未超过阈值", map[string]interface{}{"board": "半导体", "threshold": 4}, board, nil},
+		{"上涨不触发下跌规则", map[string]interface{}{"board": "半导体", "direction": "down"}, board, nil},
+		{"自选成员平均下跌", map[string]interface{}{"members": []interface{}{"600519", "000858"}, "direction": "down"}, custom, []string{"600519", "000858"}},
+		{"双向", map[string]interface{}{"members": []interface{}{"600519", "000858"}, "direction": "both"}, custom, []string{"600519", "000858"}},
+		{"没有分组数据", map[string]interface{}{"board": "半导体"}, nil, nil},
+	}
+	for _, tt := range tests {
+		result := evaluate(t, NewBoardChangeRule, tt.params, &rule.RuleContext{Group: tt.group})
+		if result.Triggered != (tt.members != nil) {
+			t.Errorf("%s: triggered = %v, message %q", tt.name, result.Triggered, result.Message)
+			continue
+		}
+		if !result.Triggered {
+			continue
+		}
+		if got, _ := result.Extra["members"].([]string); !slices.Equal(got, tt.members) {
+			t.Errorf("%s: members = %v, want %v", tt.name, got, tt.members)
+		}
+		if result.Extra["member_count"] != len(tt.group.Members) {
+			t.Errorf("%s: member_count = %v, want %d", tt.name, result.Extra["member_count"], len(tt.group.Members))
+		}
+	}
+
+	result := evaluate(t, NewBoardChangeRule, map[string]interface{}{"members": []interface{}{"600519", "000858"}, "direction": "down"}, &rule.RuleContext{Group: custom})
+	if want := "自选 板块跌 3.50%，2/2 只成员同向，领跌: 600519 -4.00%、000858 -3.00%"; result.Message != want {
+		t.Errorf("message = %q, want %q", result.Message, want)
+	}
+}
+
+func TestBoardChangeRuleMovers(t *testing.T) {
+	r, _ := NewBoardChangeRule("test", model.AlertLevelInfo, map[string]interface{}{"board": "半导体"})
+	group := &model.Group{
+		Name:  "半导体",
+		Board: &model.Board{Price: 1200, ChangePercent: 3.5},
+		Members: []*model.Stock{
+			member("603501", 101, 100), member("002371", 99, 100), member("688981", 104, 100),
+		},
+	}
+	result, err := r.Evaluate(context.Background(), &rule.RuleContext{Group: group})
+	if err != nil || !result.Triggered {
+		t.Fatalf("Evaluate = %+v, %v", result, err)
+	}
+	// 只列出同向成员，按涨跌幅排序
+	movers, _ := result.Extra["members"].([]string)
+	if !slices.Equal(movers, []string{"688981", "603501"}) {
+		t.Errorf("members = %v, want [688981 603501]", movers)
+	}
+	if want := "半导体 板块涨 3.50%，2/3 只成员同向，领涨: 688981 +4.00%、603501 +1.00%"; result.Message != want {
+		t.Errorf("message = %q, want %q", result.Message, want)
+	}
+}
+
+func TestGroupBelowMARule(t *testing.T) {
+	flat := func(code string) *model.KLineData {
+		data := &model.KLineData{Code: code, Type: model.KLineDaily}
+		for i := 0; i < 5; i++ {
+			data.Lines = append(data.Lines, model.KLine{Open: 10, High: 10, Low: 10, Close: 10})
+		}
+		return data
+	}
+	group := func(prices ...float64) *model.Group {
+		g := &model.Group{Name: "自选", KLines: make(map[string]*model.KLineData)}
+		for i, p := range prices {
+			code := []string{"600519", "000858", "601318", "000001"}[i]
+			g.Members = append(g.Members, member(code, p, 10))
+			g.KLines[code] = flat(code)
+		}
+		return g
+	}
+	params := func(extra map[string]interface{}) map[string]interface{} {
+		p := map[string]interface{}{"members": []interface{}{"600519", "000858", "601318", "000001"}, "period": 5}
+		for k, v := range extra {
+			p[k] = v
+		}
+		return p
+	}
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		group  *model.Group
+		below  []string // 跌破均线的成员，nil 表示不触发
+	}{
+		{"四只中三只跌破", params(nil), group(9, 9.5, 9.8, 11), []string{"600519", "000858", "601318"}},
+		{"一半跌破", params(nil), group(9, 9.5, 10.5, 11), nil},
+		{"一半跌破超过40%", params(map[string]interface{}{"ratio": 40}), group(9, 9.5, 10.5, 11), []string{"600519", "000858"}},
+		{"K线不足周期", params(map[string]interface{}{"period": 10}), group(9, 9, 9, 9), nil},
+		{"没有K线", params(nil), &model.Group{Members: []*model.Stock{member("600519", 9, 10)}}, nil},
+	}
+	for _, tt := range tests {
+		result := evaluate(t, NewGroupBelowMARule, tt.params, &rule.RuleContext{Group: tt.group})
+		got, _ := result.Extra["members"].([]string)
+		if result.Triggered != (tt.below != nil) || !slices.Equal(got, tt.below) {
+			t.Errorf("%s: triggered = %v, members = %v, want %v", tt.name, result.Triggered, got, tt.below)
+		}
+	}
+
+	result := evaluate(t, NewGroupBelowMARule, params(nil), &rule.RuleContext{Group: group(9, 9.5, 9.8, 11)})
+	if want := "自选 3/4 只 (75%) 跌破 MA5: 600519 -10.00%、000858 -5.00%、601318 -2.00%"; result.Message != want {
+		t.Errorf("message = %q, want %q", result.Message, want)
+	}
+}
@@ -0,0 +1,99 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+	"stock-monitor/internal/security"
+)
+
+func init() {
+	rule.GlobalRegistry.Register("index_change", NewIndexChangeRule, "指数涨跌幅")
+}
+
+// IndexChangeRule 指数涨跌幅规则：市场概况中的指数涨跌幅超过阈值时触发
+type IndexChangeRule struct {
+	name      string
+	index     string
+	threshold float64 // 涨跌幅阈值 %
+	direction string  // up / down / both
+	level     model.AlertLevel
+}
+
+// NewIndexChangeRule 创建规则
+//
+// 参数 index 为指数代码（默认 000001 上证指数，可带 sh/sz 前缀），需在市场概况跟踪的指数中。
+func NewIndexChangeRule(name string, level model.AlertLevel, params map[string]interface{}) (rule.Rule, error) {
+	index, err := security.ParseIndex(stringParam(params, "index", "000001"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &IndexChangeRule{
+		name:      name,
+		index:     index.Canonical(),
+		threshold: floatParam(params, "threshold", 2),
+		direction: stringParam(params, "direction", "down"),
+		level:     level,
+	}, nil
+}
+
+func (r *IndexChangeRule) Name() string { return r.name }
+func (r *IndexChangeRule) MarketWide()  {}
+
+func (r *IndexChangeRule) Description() string {
+	switch r.direction {
+	case "up":
+		return fmt.Sprintf("%s 涨幅超过 %.2f%%", r.index, r.threshold)
+	case "both":
+		return fmt.Sprintf("%s 涨跌幅超过 %.2f%%", r.index, r.threshold)
+	}
+	return fmt.Sprintf("%s 跌幅超过 %.2f%%", r.index, r.threshold)
+}
+
+func (r *IndexChangeRule) Validate() error {
+	if r.threshold <= 0 {
+		return fmt.Errorf("threshold must be positive")
+	}
+	switch r.direction {
+	case "up", "down", "both":
+	default:
+		return fmt.Errorf("unknown direction: %s", r.direction)
+	}
+	return nil
+}
+
+func (r *IndexChangeRule) Evaluate(ctx context.Context, ruleCtx *rule.RuleContext) (*rule.RuleResult, error) {
+	if ruleCtx.Market == nil {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	index := ruleCtx.Market.Index(r.index)
+	if index == nil || index.PreClose <= 0 {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	change := index.ChangePercent()
+	if math.Abs(change) <= r.threshold ||
+		(r.direction == "up" && change < 0) || (r.direction == "down" && change > 0) {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	verb := "涨"
+	if change < 0 {
+		verb = "跌"
+	}
+	return &rule.RuleResult{
+		Triggered: true,
+		RuleName:  r.name,
+		Level:     r.level,
+		Message:   fmt.Sprintf("%s %s %.2f%%，报 %.2f", index.Name, verb, math.Abs(change), index.Price),
+		Extra: map[string]interface{}{
+			"index":          r.index,
+			"change_percent": change,
+			"price":          index.Price,
+		},
+	}, nil
+}
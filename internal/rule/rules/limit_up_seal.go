@@ -0,0 +1,116 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+	"stock-monitor/internal/security"
+)
+
+func init() {
+	rule.GlobalRegistry.Register("limit_up_seal", NewLimitUpSealRule, "涨停封单减少")
+}
+
+// LimitUpSealRule 涨停封单规则：涨停封单金额从阈值以上降到阈值以下（含开板）时触发
+type LimitUpSealRule struct {
+	name      string
+	minAmount float64 // 封单金额阈值 元
+	stockCode string
+	level     model.AlertLevel
+
+	mu     sync.Mutex
+	sealed map[string]bool // 上一次评估时封单是否达标
+}
+
+// NewLimitUpSealRule 创建规则
+func NewLimitUpSealRule(name string, level model.AlertLevel, params map[string]interface{}) (rule.Rule, error) {
+	stockCode, _ := params["stock_code"].(string)
+
+	return &LimitUpSealRule{
+		name:      name,
+		minAmount: floatParam(params, "min_amount", 1e8),
+		stockCode: stockCode,
+		level:     level,
+		sealed:    make(map[string]bool),
+	}, nil
+}
+
+func (r *LimitUpSealRule) Name() string { return r.name }
+
+func (r *LimitUpSealRule) Description() string {
+	return fmt.Sprintf("涨停封单低于 %.0f 万元", r.minAmount/1e4)
+}
+
+func (r *LimitUpSealRule) Validate() error {
+	if r.minAmount <= 0 {
+		return fmt.Errorf("min_amount must be positive")
+	}
+	return nil
+}
+
+func (r *LimitUpSealRule) Evaluate(ctx context.Context, ruleCtx *rule.RuleContext) (*rule.RuleResult, error) {
+	stock := ruleCtx.Stock
+	if r.stockCode != "" && stock.Code != r.stockCode {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	limitUp, tick := limitUpPrice(stock)
+	if stock.Depth == nil || limitUp <= 0 {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	atLimit := math.Abs(stock.Price-limitUp) < tick/2
+	var seal int64
+	if atLimit && stock.Depth.Bids[0].Price >= limitUp-tick/2 {
+		seal = stock.Depth.Bids[0].Volume
+	}
+	amount := float64(seal) * limitUp
+	sealed := atLimit && amount >= r.minAmount
+
+	r.mu.Lock()
+	wasSealed := r.sealed[stock.Code]
+	r.sealed[stock.Code] = sealed
+	r.mu.Unlock()
+
+	if !wasSealed || sealed {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	message := fmt.Sprintf("%s 涨停封单降至 %d 手 (%.0f 万元)，低于 %.0f 万元",
+		stock.Name, seal/100, amount/1e4, r.minAmount/1e4)
+	if !atLimit {
+		message = fmt.Sprintf("%s 涨停板打开，现价 %.2f (涨停价 %.2f)", stock.Name, stock.Price, limitUp)
+	}
+	return &rule.RuleResult{
+		Triggered: true,
+		RuleName:  r.name,
+		Level:     r.level,
+		Message:   message,
+		Extra: map[string]interface{}{
+			"limit_up":    limitUp,
+			"seal_volume": seal,
+			"seal_amount": amount,
+			"opened":      !atLimit,
+		},
+	}, nil
+}
+
+// limitUpPrice 涨停价及最小报价单位：优先使用行情提供的涨停价，否则按板块涨跌幅由昨收计算
+func limitUpPrice(stock *model.Stock) (float64, float64) {
+	sec, err := security.Parse(stock.Code)
+	if err != nil {
+		return stock.LimitUp, 0.01
+	}
+	if stock.LimitUp > 0 {
+		return stock.LimitUp, sec.Tick()
+	}
+	ratio := sec.LimitRatio(stock.Name)
+	if ratio == 0 || stock.PreClose <= 0 {
+		return 0, sec.Tick()
+	}
+	up, _ := security.LimitPrices(stock.PreClose, ratio, sec.Tick())
+	return up, sec.Tick()
+}
@@ -0,0 +1,101 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+)
+
+func init() {
+	rule.GlobalRegistry.Register("market_breadth", NewMarketBreadthRule, "市场情绪")
+}
+
+// 市场情绪规则的统计指标
+var breadthMetrics = map[string]struct {
+	label string
+	value func(m *model.MarketOverview) float64
+}{
+	"limit_up":      {"涨停家数", func(m *model.MarketOverview) float64 { return float64(m.LimitUp) }},
+	"limit_down":    {"跌停家数", func(m *model.MarketOverview) float64 { return float64(m.LimitDown) }},
+	"advancing":     {"上涨家数", func(m *model.MarketOverview) float64 { return float64(m.Advancing) }},
+	"declining":     {"下跌家数", func(m *model.MarketOverview) float64 { return float64(m.Declining) }},
+	"advance_ratio": {"上涨比例(%)", func(m *model.MarketOverview) float64 { return m.AdvanceRatio() }},
+	"amount":        {"成交额(亿)", func(m *model.MarketOverview) float64 { return m.Amount / 1e8 }},
+}
+
+// MarketBreadthRule 市场情绪规则：涨跌停家数、涨跌家数或两市成交额高于（低于）阈值时触发
+type MarketBreadthRule struct {
+	name      string
+	metric    string
+	op        string // above / below
+	threshold float64
+	level     model.AlertLevel
+}
+
+// NewMarketBreadthRule 创建规则
+//
+// 参数 metric 为 limit_up / limit_down / advancing / declining / advance_ratio / amount（亿元）。
+func NewMarketBreadthRule(name string, level model.AlertLevel, params map[string]interface{}) (rule.Rule, error) {
+	return &MarketBreadthRule{
+		name:      name,
+		metric:    stringParam(params, "metric", "limit_down"),
+		op:        stringParam(params, "op", "above"),
+		threshold: floatParam(params, "threshold", 100),
+		level:     level,
+	}, nil
+}
+
+func (r *MarketBreadthRule) Name() string { return r.name }
+func (r *MarketBreadthRule) MarketWide()  {}
+
+func (r *MarketBreadthRule) Description() string {
+	op := "超过"
+	if r.op == "below" {
+		op = "低于"
+	}
+	return fmt.Sprintf("%s%s %.0f", breadthMetrics[r.metric].label, op, r.threshold)
+}
+
+func (r *MarketBreadthRule) Validate() error {
+	if _, ok := breadthMetrics[r.metric]; !ok {
+		return fmt.Errorf("unknown metric: %s", r.metric)
+	}
+	switch r.op {
+	case "above", "below":
+	default:
+		return fmt.Errorf("unknown op: %s", r.op)
+	}
+	return nil
+}
+
+func (r *MarketBreadthRule) Evaluate(ctx context.Context, ruleCtx *rule.RuleContext) (*rule.RuleResult, error) {
+	m := ruleCtx.Market
+	if m == nil || m.Advancing+m.Declining+m.Unchanged == 0 {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	metric := breadthMetrics[r.metric]
+	value := metric.value(m)
+	if (r.op == "above" && value <= r.threshold) || (r.op == "below" && value >= r.threshold) {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	return &rule.RuleResult{
+		Triggered: true,
+		RuleName:  r.name,
+		Level:     r.level,
+		Message: fmt.Sprintf("%s %.0f (上涨 %d / 下跌 %d，涨停 %d / 跌停 %d，成交额 %.0f 亿)",
+			metric.label, value, m.Advancing, m.Declining, m.LimitUp, m.LimitDown, m.Amount/1e8),
+		Extra: map[string]interface{}{
+			"metric":     r.metric,
+			"value":      value,
+			"advancing":  m.Advancing,
+			"declining":  m.Declining,
+			"limit_up":   m.LimitUp,
+			"limit_down": m.LimitDown,
+			"amount":     m.Amount,
+		},
+	}, nil
+}
@@ -0,0 +1,80 @@
+package rules
+
+import (
+	"context"
+	"testing"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+)
+
+// evaluate 创建规则并评估一次，返回评估结果
+func evaluate(t *testing.T, factory rule.RuleFactory, params map[string]interface{}, ruleCtx *rule.RuleContext) *rule.RuleResult {
+	t.Helper()
+	r, err := factory("test", model.AlertLevelInfo, params)
+	if err != nil {
+		t.Fatalf("create rule: %v", err)
+	}
+	if err := r.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	result, err := r.Evaluate(context.Background(), ruleCtx)
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	return result
+}
+
+func TestMarketBreadthRule(t *testing.T) {
+	m := &model.MarketOverview{Advancing: 800, Declining: 4200, Unchanged: 100, LimitUp: 20, LimitDown: 150, Amount: 5800e8}
+	const summary = " (上涨 800 / 下跌 4200，涨停 20 / 跌停 150，成交额 5800 亿)"
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		market  *model.MarketOverview
+		message string // 不触发时为空
+	}{
+		{"跌停超过100", map[string]interface{}{"metric": "limit_down", "threshold": 100}, m, "跌停家数 150" + summary},
+		{"跌停未超过200", map[string]interface{}{"metric": "limit_down", "threshold": 200}, m, ""},
+		{"成交额低于6000亿", map[string]interface{}{"metric": "amount", "op": "below", "threshold": 6000}, m, "成交额(亿) 5800" + summary},
+		{"上涨比例低于20%", map[string]interface{}{"metric": "advance_ratio", "op": "below", "threshold": 20}, m, "上涨比例(%) 16" + summary},
+		{"没有成交数据", map[string]interface{}{"metric": "limit_down", "threshold": 100}, &model.MarketOverview{}, ""},
+	}
+	for _, tt := range tests {
+		result := evaluate(t, NewMarketBreadthRule, tt.params, &rule.RuleContext{Market: tt.market})
+		if result.Triggered != (tt.message != "") || result.Message != tt.message {
+			t.Errorf("%s: got (%v, %q), want %q", tt.name, result.Triggered, result.Message, tt.message)
+		}
+		if result.Triggered && result.Extra["metric"] != tt.params["metric"] {
+			t.Errorf("%s: metric = %v, want %v", tt.name, result.Extra["metric"], tt.params["metric"])
+		}
+	}
+}
+
+func TestIndexChangeRule(t *testing.T) {
+	market := &model.MarketOverview{Indices: []*model.Stock{
+		{Code: "sh000001", Name: "上证指数", Price: 2900, PreClose: 3000}, // -3.33%
+		{Code: "399006", Name: "创业板指", Price: 1850, PreClose: 1800},   // +2.78%
+	}}
+	const (
+		shDown = "上证指数 跌 3.33%，报 2900.00"
+		cybUp  = "创业板指 涨 2.78%，报 1850.00"
+	)
+	cases := map[string]struct {
+		params  map[string]interface{}
+		message string // 不触发时为空
+	}{
+		"上证大跌":      {map[string]interface{}{}, shDown},
+		"上证跌幅未超过阈值": {map[string]interface{}{"threshold": 4}, ""},
+		"上证只看涨幅":    {map[string]interface{}{"direction": "up"}, ""},
+		"创业板指上涨":    {map[string]interface{}{"index": "399006", "direction": "up"}, cybUp},
+		"创业板指双向":    {map[string]interface{}{"index": "sz399006", "direction": "both"}, cybUp},
+		"未跟踪的指数":    {map[string]interface{}{"index": "000688"}, ""},
+	}
+	for name, c := range cases {
+		result := evaluate(t, NewIndexChangeRule, c.params, &rule.RuleContext{Market: market})
+		if result.Message != c.message || result.Triggered != (c.message != "") {
+			t.Errorf("%s: got (%v, %q), want %q", name, result.Triggered, result.Message, c.message)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+)
+
+func init() {
+	rule.GlobalRegistry.Register("order_imbalance", NewOrderImbalanceRule, "买卖盘失衡")
+}
+
+// OrderImbalanceRule 买卖盘失衡规则：前N档买卖盘失衡度超过阈值时触发
+type OrderImbalanceRule struct {
+	name      string
+	threshold float64 // 失衡度阈值 0~1
+	levels    int
+	side      string // bid 买盘占优 / ask 卖盘占优 / both
+	stockCode string
+	level     model.AlertLevel
+}
+
+// NewOrderImbalanceRule 创建规则
+func NewOrderImbalanceRule(name string, level model.AlertLevel, params map[string]interface{}) (rule.Rule, error) {
+	stockCode, _ := params["stock_code"].(string)
+
+	return &OrderImbalanceRule{
+		name:      name,
+		threshold: floatParam(params, "threshold", 0.5),
+		levels:    intParam(params, "levels", model.DepthLevels),
+		side:      stringParam(params, "side", "both"),
+		stockCode: stockCode,
+		level:     level,
+	}, nil
+}
+
+func (r *OrderImbalanceRule) Name() string { return r.name }
+
+func (r *OrderImbalanceRule) Description() string {
+	return fmt.Sprintf("前%d档买卖盘失衡度超过 %.2f", r.levels, r.threshold)
+}
+
+func (r *OrderImbalanceRule) Validate() error {
+	if r.threshold <= 0 || r.threshold >= 1 {
+		return fmt.Errorf("threshold must be between 0 and 1")
+	}
+	if r.levels <= 0 || r.levels > model.DepthLevels {
+		return fmt.Errorf("levels must be between 1 and %d", model.DepthLevels)
+	}
+	switch r.side {
+	case "bid", "ask", "both":
+	default:
+		return fmt.Errorf("unknown side: %s", r.side)
+	}
+	return nil
+}
+
+func (r *OrderImbalanceRule) Evaluate(ctx context.Context, ruleCtx *rule.RuleContext) (*rule.RuleResult, error) {
+	stock := ruleCtx.Stock
+	if r.stockCode != "" && stock.Code != r.stockCode {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	if stock.Depth == nil {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	imbalance := stock.Depth.Imbalance(r.levels)
+	if math.Abs(imbalance) < r.threshold ||
+		(r.side == "bid" && imbalance < 0) || (r.side == "ask" && imbalance > 0) {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	dominant := "买盘"
+	if imbalance < 0 {
+		dominant = "卖盘"
+	}
+	bid, ask := stock.Depth.BidVolume(r.levels), stock.Depth.AskVolume(r.levels)
+	return &rule.RuleResult{
+		Triggered: true,
+		RuleName:  r.name,
+		Level:     r.level,
+		Message: fmt.Sprintf("%s 前%d档%s占优，失衡度 %.2f (买 %d 手 / 卖 %d 手)",
+			stock.Name, r.levels, dominant, imbalance, bid/100, ask/100),
+		Extra: map[string]interface{}{
+			"imbalance":  imbalance,
+			"bid_volume": bid,
+			"ask_volume": ask,
+			"levels":     r.levels,
+		},
+	}, nil
+}
@@ -0,0 +1,155 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+)
+
+func init() {
+	rule.GlobalRegistry.Register("order_wall", NewOrderWallRule, "大单挂单")
+}
+
+// OrderWallRule 大单挂单规则：某档挂单量达到其余档位均量的倍数且金额超过阈值，
+// 且上一次评估时该价位尚无大单时触发
+type OrderWallRule struct {
+	name      string
+	multiple  float64 // 相对其余档位均量的倍数
+	minAmount float64 // 最小挂单金额 元
+	side      string  // bid / ask / both
+	stockCode string
+	level     model.AlertLevel
+
+	mu    sync.Mutex
+	walls map[string]map[string]bool // code -> "bid@10.50" -> 存在
+}
+
+// NewOrderWallRule 创建规则
+func NewOrderWallRule(name string, level model.AlertLevel, params map[string]interface{}) (rule.Rule, error) {
+	stockCode, _ := params["stock_code"].(string)
+
+	return &OrderWallRule{
+		name:      name,
+		multiple:  floatParam(params, "multiple", 5),
+		minAmount: floatParam(params, "min_amount", 5e6),
+		side:      stringParam(params, "side", "both"),
+		stockCode: stockCode,
+		level:     level,
+		walls:     make(map[string]map[string]bool),
+	}, nil
+}
+
+func (r *OrderWallRule) Name() string { return r.name }
+
+func (r *OrderWallRule) Description() string {
+	return fmt.Sprintf("盘口出现 %.0f 倍均量且超过 %.0f 万元的大单", r.multiple, r.minAmount/1e4)
+}
+
+func (r *OrderWallRule) Validate() error {
+	if r.multiple <= 1 {
+		return fmt.Errorf("multiple must be greater than 1")
+	}
+	if r.minAmount < 0 {
+		return fmt.Errorf("min_amount must not be negative")
+	}
+	switch r.side {
+	case "bid", "ask", "both":
+	default:
+		return fmt.Errorf("unknown side: %s", r.side)
+	}
+	return nil
+}
+
+// orderWall 识别到的大单
+type orderWall struct {
+	side  string
+	index int
+	level model.DepthLevel
+}
+
+func (w orderWall) key() string {
+	return fmt.Sprintf("%s@%.3f", w.side, w.level.Price)
+}
+
+func (r *OrderWallRule) Evaluate(ctx context.Context, ruleCtx *rule.RuleContext) (*rule.RuleResult, error) {
+	stock := ruleCtx.Stock
+	if r.stockCode != "" && stock.Code != r.stockCode {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	if stock.Depth == nil {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	var walls []orderWall
+	if r.side != "ask" {
+		walls = append(walls, r.findWalls("bid", stock.Depth.Bids[:])...)
+	}
+	if r.side != "bid" {
+		walls = append(walls, r.findWalls("ask", stock.Depth.Asks[:])...)
+	}
+
+	current := make(map[string]bool, len(walls))
+	for _, w := range walls {
+		current[w.key()] = true
+	}
+	r.mu.Lock()
+	prev := r.walls[stock.Code]
+	r.walls[stock.Code] = current
+	r.mu.Unlock()
+
+	for _, w := range walls {
+		if prev[w.key()] {
+			continue
+		}
+		sideName := "买"
+		if w.side == "ask" {
+			sideName = "卖"
+		}
+		amount := w.level.Price * float64(w.level.Volume)
+		return &rule.RuleResult{
+			Triggered: true,
+			RuleName:  r.name,
+			Level:     r.level,
+			Message: fmt.Sprintf("%s %s%d 档 %.2f 出现大单 %d 手 (%.0f 万元)",
+				stock.Name, sideName, w.index+1, w.level.Price, w.level.Volume/100, amount/1e4),
+			Extra: map[string]interface{}{
+				"side":   w.side,
+				"level":  w.index + 1,
+				"price":  w.level.Price,
+				"volume": w.level.Volume,
+				"amount": amount,
+			},
+		}, nil
+	}
+	return &rule.RuleResult{Triggered: false}, nil
+}
+
+// findWalls 查找挂单量达到其余档位均量 multiple 倍且金额达标的档位
+func (r *OrderWallRule) findWalls(side string, levels []model.DepthLevel) []orderWall {
+	var total int64
+	var count int
+	for _, l := range levels {
+		if l.Volume > 0 {
+			total += l.Volume
+			count++
+		}
+	}
+	if count < 2 {
+		return nil
+	}
+
+	var walls []orderWall
+	for i, l := range levels {
+		if l.Volume <= 0 || l.Price <= 0 {
+			continue
+		}
+		othersAvg := float64(total-l.Volume) / float64(count-1)
+		if float64(l.Volume) >= othersAvg*r.multiple && l.Price*float64(l.Volume) >= r.minAmount {
+			walls = append(walls, orderWall{side: side, index: i, level: l})
+		}
+	}
+	return walls
+}
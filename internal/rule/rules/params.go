@@ -0,0 +1,103 @@
+package rules
+
+import (
+	"strings"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/security"
+)
+
+// intParam 读取整型参数（兼容JSON解码后的float64）
+func intParam(params map[string]interface{}, key string, def int) int {
+	switch v := params[key].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return def
+}
+
+// floatParam 读取浮点参数
+func floatParam(params map[string]interface{}, key string, def float64) float64 {
+	switch v := params[key].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	}
+	return def
+}
+
+// stringParam 读取字符串参数
+func stringParam(params map[string]interface{}, key, def string) string {
+	if v, ok := params[key].(string); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+// stringListParam 读取字符串列表参数，支持数组或逗号分隔字符串
+func stringListParam(params map[string]interface{}, key string) []string {
+	var list []string
+	switch v := params[key].(type) {
+	case []string:
+		list = v
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+	case string:
+		list = strings.Split(v, ",")
+	}
+
+	result := make([]string, 0, len(list))
+	for _, s := range list {
+		if s = strings.TrimSpace(s); s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// klineTypeParam 读取K线类型参数，默认日K
+func klineTypeParam(params map[string]interface{}) model.KLineType {
+	return model.KLineType(stringParam(params, "kline_type", string(model.KLineDaily)))
+}
+
+// lastCompletedIndex 返回最后一根已完成K线的下标，没有时返回-1
+//
+// 优先使用数据源标记的 Forming；未标记时按行情时间判断：
+// 日K及以上周期最后一根与行情同一天且尚未收盘（按证券所属市场）时视为未完成，
+// 分钟K线时间(结束时间)晚于行情时间时视为未完成。
+func lastCompletedIndex(data *model.KLineData, quoteTime time.Time) int {
+	n := len(data.Lines)
+	if n == 0 {
+		return -1
+	}
+	if data.Forming {
+		return n - 2
+	}
+	last := data.Lines[n-1].Time
+	if last.IsZero() || quoteTime.IsZero() {
+		return n - 1
+	}
+
+	if data.Type.Minutes() > 0 {
+		if last.After(quoteTime) {
+			return n - 2
+		}
+		return n - 1
+	}
+	market := security.MarketOf(data.Code)
+	quoteTime = market.In(quoteTime)
+	y1, m1, d1 := last.Date()
+	y2, m2, d2 := quoteTime.Date()
+	if y1 == y2 && m1 == m2 && d1 == d2 && quoteTime.Before(market.CloseTime(quoteTime)) {
+		return n - 2
+	}
+	return n - 1
+}
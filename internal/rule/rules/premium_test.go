@@ -0,0 +1,100 @@
+package rules
+
+import (
+	"math"
+	"testing"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+)
+
+func TestConvertiblePremiumRule(t *testing.T) {
+	bond := func(price, underlyingPrice, conversionPrice float64) *model.Stock {
+		info := model.NewBondInfo(price, underlyingPrice, conversionPrice)
+		info.UnderlyingCode, info.UnderlyingName = "601009", "南京银行"
+		return &model.Stock{Code: "113050", Name: "南银转债", Price: price, Bond: info}
+	}
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		stock   *model.Stock
+		premium float64 // 触发时 Extra 中的溢价率，NaN 表示不触发
+	}{
+		{"折价转债", map[string]interface{}{"threshold": 0}, bond(110, 12, 10), -8.33},
+		{"溢价率高于阈值", map[string]interface{}{"threshold": 5}, bond(130, 12, 10), math.NaN()},
+		{"溢价率低于阈值", map[string]interface{}{"threshold": 10}, bond(130, 12, 10), 8.33},
+		{"高溢价", map[string]interface{}{"threshold": 5, "direction": "above"}, bond(130, 12, 10), 8.33},
+		{"高溢价未超过阈值", map[string]interface{}{"threshold": 10, "direction": "above"}, bond(130, 12, 10), math.NaN()},
+		{"缺少转股价", map[string]interface{}{"threshold": 10}, bond(110, 12, 0), math.NaN()},
+		{"没有转债数据", map[string]interface{}{"threshold": 10}, &model.Stock{Code: "113050", Price: 110}, math.NaN()},
+		{"其他代码", map[string]interface{}{"threshold": 10, "stock_code": "123107"}, bond(110, 12, 10), math.NaN()},
+	}
+	for _, tt := range tests {
+		result := evaluate(t, NewConvertiblePremiumRule, tt.params, &rule.RuleContext{Stock: tt.stock})
+		if math.IsNaN(tt.premium) {
+			if result.Triggered {
+				t.Errorf("%s: unexpected alert %q", tt.name, result.Message)
+			}
+			continue
+		}
+		if !result.Triggered {
+			t.Errorf("%s: not triggered", tt.name)
+			continue
+		}
+		if got, _ := result.Extra["premium"].(float64); math.Abs(got-tt.premium) > 0.01 {
+			t.Errorf("%s: premium = %.4f, want %.2f", tt.name, got, tt.premium)
+		}
+		if result.Extra["underlying_code"] != "601009" {
+			t.Errorf("%s: underlying_code = %v", tt.name, result.Extra["underlying_code"])
+		}
+	}
+
+	result := evaluate(t, NewConvertiblePremiumRule, map[string]interface{}{"threshold": 0}, &rule.RuleContext{Stock: bond(110, 12, 10)})
+	want := "南银转债 转股溢价率 -8.33% (现价 110.000，转股价值 120.00，正股 南京银行 12.00)"
+	if result.Message != want {
+		t.Errorf("message = %q, want %q", result.Message, want)
+	}
+}
+
+func TestETFPremiumRule(t *testing.T) {
+	etf := func(price, iopv float64) *model.Stock {
+		return &model.Stock{Code: "513100", Name: "纳指ETF", Price: price, ETF: model.NewETFInfo(price, iopv)}
+	}
+	// 触发时消息区分溢价和折价，不触发时 message 为空
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		stock   *model.Stock
+		message string
+	}{
+		{"溢价超过1%", map[string]interface{}{}, etf(1.52, 1.48), "纳指ETF 溢价 2.70% (现价 1.520，参考净值 1.4800)"},
+		{"溢价未超过阈值", map[string]interface{}{"threshold": 3}, etf(1.52, 1.48), ""},
+		{"折价不触发溢价规则", map[string]interface{}{}, etf(1.44, 1.48), ""},
+		{"折价", map[string]interface{}{"direction": "below"}, etf(1.44, 1.48), "纳指ETF 折价 2.70% (现价 1.440，参考净值 1.4800)"},
+		{"溢价不触发折价规则", map[string]interface{}{"direction": "below"}, etf(1.52, 1.48), ""},
+		{"双向", map[string]interface{}{"direction": "both"}, etf(1.44, 1.48), "纳指ETF 折价 2.70% (现价 1.440，参考净值 1.4800)"},
+		{"没有参考净值", map[string]interface{}{}, etf(1.52, 0), ""},
+		{"没有ETF数据", map[string]interface{}{}, &model.Stock{Code: "513100", Price: 1.52}, ""},
+	}
+	for _, tt := range tests {
+		result := evaluate(t, NewETFPremiumRule, tt.params, &rule.RuleContext{Stock: tt.stock})
+		if result.Triggered != (tt.message != "") || result.Message != tt.message {
+			t.Errorf("%s: got (%v, %q), want %q", tt.name, result.Triggered, result.Message, tt.message)
+		}
+		if result.Triggered && result.Extra["iopv"] != 1.48 {
+			t.Errorf("%s: iopv = %v, want 1.48", tt.name, result.Extra["iopv"])
+		}
+	}
+}
+
+func TestETFPremiumRuleValidate(t *testing.T) {
+	for _, params := range []map[string]interface{}{
+		{"threshold": 0},
+		{"direction": "sideways"},
+	} {
+		r, _ := NewETFPremiumRule("test", model.AlertLevelInfo, params)
+		if err := r.Validate(); err == nil {
+			t.Errorf("Validate(%v) = nil, want error", params)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+	"stock-monitor/internal/security"
+)
+
+func init() {
+	rule.GlobalRegistry.Register("relative_strength", NewRelativeStrengthRule, "相对强弱")
+}
+
+// 相对强弱规则触发方式
+const (
+	rsCrossZero = "cross_zero" // 相对强弱上穿/下穿0轴
+	rsNewHigh   = "new_high"   // 相对强弱创N日新高
+	rsBoth      = "both"
+)
+
+// RelativeStrengthRule 相对强弱规则：个股N周期涨幅减去基准指数N周期涨幅
+type RelativeStrengthRule struct {
+	name       string
+	benchmark  string
+	period     int
+	highPeriod int
+	mode       string
+	stockCode  string
+	klineType  model.KLineType
+	level      model.AlertLevel
+}
+
+// NewRelativeStrengthRule 创建规则
+//
+// 参数 benchmark 为基准指数代码（默认 000300 沪深300，可带 sh/sz 前缀），
+// period 为涨幅计算周期，high_period 为新高判断周期。
+func NewRelativeStrengthRule(name string, level model.AlertLevel, params map[string]interface{}) (rule.Rule, error) {
+	stockCode, _ := params["stock_code"].(string)
+
+	benchmark, err := security.ParseIndex(stringParam(params, "benchmark", "000300"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &RelativeStrengthRule{
+		name:       name,
+		benchmark:  benchmark.Symbol(),
+		period:     intParam(params, "period", 20),
+		highPeriod: intParam(params, "high_period", 20),
+		mode:       stringParam(params, "mode", rsBoth),
+		stockCode:  stockCode,
+		klineType:  klineTypeParam(params),
+		level:      level,
+	}, nil
+}
+
+func (r *RelativeStrengthRule) Name() string               { return r.name }
+func (r *RelativeStrengthRule) StockCode() string          { return r.stockCode }
+func (r *RelativeStrengthRule) KLineType() model.KLineType { return r.klineType }
+func (r *RelativeStrengthRule) BenchmarkCode() string      { return r.benchmark }
+
+func (r *RelativeStrengthRule) Description() string {
+	return fmt.Sprintf("%s K线 %d周期相对 %s 强弱", r.klineType, r.period, r.benchmark)
+}
+
+func (r *RelativeStrengthRule) Validate() error {
+	if r.period <= 0 || r.highPeriod <= 0 {
+		return fmt.Errorf("period must be positive")
+	}
+	switch r.mode {
+	case rsCrossZero, rsNewHigh, rsBoth:
+	default:
+		return fmt.Errorf("unknown mode: %s", r.mode)
+	}
+	return nil
+}
+
+func (r *RelativeStrengthRule) Evaluate(ctx context.Context, ruleCtx *rule.RuleContext) (*rule.RuleResult, error) {
+	if r.stockCode != "" && ruleCtx.Stock.Code != r.stockCode {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	if ruleCtx.KLines == nil || ruleCtx.Benchmark == nil {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	// 个股与基准各自去掉未完成的K线后再对齐
+	quoteTime := ruleCtx.Stock.Time
+	stockLines := ruleCtx.KLines.Lines[:lastCompletedIndex(ruleCtx.KLines, quoteTime)+1]
+	benchLines := ruleCtx.Benchmark.Lines[:lastCompletedIndex(ruleCtx.Benchmark, quoteTime)+1]
+	rs := relativeStrength(stockLines, benchLines, r.period)
+	if len(rs) < 2 {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	cur, prev := rs[len(rs)-1], rs[len(rs)-2]
+
+	var message, event string
+	if r.mode != rsNewHigh {
+		if prev <= 0 && cur > 0 {
+			event = "cross_above_zero"
+			message = fmt.Sprintf("%s %d周期相对强弱上穿0轴 (%.2f%%)，开始跑赢 %s", ruleCtx.Stock.Name, r.period, cur, r.benchmark)
+		} else if prev >= 0 && cur < 0 {
+			event = "cross_below_zero"
+			message = fmt.Sprintf("%s %d周期相对强弱下穿0轴 (%.2f%%)，开始跑输 %s", ruleCtx.Stock.Name, r.period, cur, r.benchmark)
+		}
+	}
+	if message == "" && r.mode != rsCrossZero && len(rs) > r.highPeriod {
+		high := true
+		for _, v := range rs[len(rs)-1-r.highPeriod : len(rs)-1] {
+			if v >= cur {
+				high = false
+				break
+			}
+		}
+		if high {
+			event = "new_high"
+			message = fmt.Sprintf("%s 相对 %s 强弱创%d周期新高 (%.2f%%)", ruleCtx.Stock.Name, r.benchmark, r.highPeriod, cur)
+		}
+	}
+
+	if message == "" {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	return &rule.RuleResult{
+		Triggered: true,
+		RuleName:  r.name,
+		Level:     r.level,
+		Message:   message,
+		Extra: map[string]interface{}{
+			"event":             event,
+			"relative_strength": cur,
+			"benchmark":         r.benchmark,
+			"period":            r.period,
+		},
+	}, nil
+}
+
+// relativeStrength 按K线时间对齐个股与基准，计算逐根的N周期涨幅差(百分点)
+func relativeStrength(stock, bench []model.KLine, period int) []float64 {
+	benchClose := make(map[int64]float64, len(bench))
+	for _, k := range bench {
+		benchClose[k.Time.Unix()] = k.Close
+	}
+
+	var sc, bc []float64
+	for _, k := range stock {
+		if c, ok := benchClose[k.Time.Unix()]; ok {
+			sc = append(sc, k.Close)
+			bc = append(bc, c)
+		}
+	}
+
+	var rs []float64
+	for i := period; i < len(sc); i++ {
+		if sc[i-period] <= 0 || bc[i-period] <= 0 {
+			continue
+		}
+		stockRet := (sc[i]/sc[i-period] - 1) * 100
+		benchRet := (bc[i]/bc[i-period] - 1) * 100
+		rs = append(rs, stockRet-benchRet)
+	}
+	return rs
+}
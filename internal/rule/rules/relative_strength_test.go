@@ -0,0 +1,86 @@
+package rules
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+)
+
+// dailyCloses 从 2024-01-02 起按日构造收盘价序列，close 为0的日期跳过（停牌）
+func dailyCloses(code string, closes ...float64) *model.KLineData {
+	data := &model.KLineData{Code: code, Type: model.KLineDaily}
+	for i, c := range closes {
+		if c == 0 {
+			continue
+		}
+		day := time.Date(2024, 1, 2+i, 0, 0, 0, 0, time.Local)
+		data.Lines = append(data.Lines, model.KLine{Time: day, Open: c, High: c, Low: c, Close: c})
+	}
+	return data
+}
+
+func TestRelativeStrengthAlignment(t *testing.T) {
+	// 基准缺少第2天，个股缺少第5天，只用两边都有的日期
+	stock := dailyCloses("600519", 10, 11, 12, 13, 0)
+	bench := dailyCloses("sh000300", 100, 0, 110, 121, 130)
+
+	got := relativeStrength(stock.Lines, bench.Lines, 1)
+	want := []float64{20 - 10, (13.0/12-1)*100 - 10}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Errorf("rs[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRelativeStrengthRule(t *testing.T) {
+	stock := &model.Stock{Code: "600519", Name: "贵州茅台"}
+
+	// 已完成K线的相对强弱一直在0轴下方，只有未完成的最后一根上穿
+	forming := dailyCloses("600519", 10, 10, 10, 11)
+	forming.Forming = true
+	r := mustRule(t, NewRelativeStrengthRule, map[string]interface{}{"period": 1, "mode": "cross_zero"})
+	result, err := r.Evaluate(context.Background(), &rule.RuleContext{
+		Stock: stock, KLines: forming, Benchmark: dailyCloses("sh000300", 100, 101, 102, 102),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Triggered {
+		t.Errorf("forming bar triggered: %s", result.Message)
+	}
+
+	// 同样的K线收盘后触发
+	result, _ = r.Evaluate(context.Background(), &rule.RuleContext{
+		Stock: stock, KLines: dailyCloses("600519", 10, 10, 10, 11), Benchmark: dailyCloses("sh000300", 100, 101, 102, 102),
+	})
+	if !result.Triggered || result.Extra["event"] != "cross_above_zero" || !strings.Contains(result.Message, "开始跑赢 sh000300") {
+		t.Errorf("completed bar result = %+v", result)
+	}
+
+	// 基准的未完成K线同样不参与计算
+	bench := dailyCloses("sh000300", 100, 101, 102, 102, 90)
+	bench.Forming = true
+	result, _ = r.Evaluate(context.Background(), &rule.RuleContext{
+		Stock: stock, KLines: dailyCloses("600519", 10, 10, 10, 11), Benchmark: bench,
+	})
+	if !result.Triggered || result.Extra["event"] != "cross_above_zero" {
+		t.Errorf("forming benchmark result = %+v", result)
+	}
+
+	high := mustRule(t, NewRelativeStrengthRule, map[string]interface{}{"period": 1, "high_period": 2, "mode": "new_high"})
+	result, _ = high.Evaluate(context.Background(), &rule.RuleContext{
+		Stock: stock, KLines: dailyCloses("600519", 10, 10.2, 10.2, 11), Benchmark: dailyCloses("sh000300", 100, 100, 100, 100),
+	})
+	if !result.Triggered || result.Extra["event"] != "new_high" || !strings.Contains(result.Message, "创2周期新高") {
+		t.Errorf("new high result = %+v", result)
+	}
+}
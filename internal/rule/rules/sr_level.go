@@ -0,0 +1,155 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"stock-monitor/internal/indicator"
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+)
+
+func init() {
+	rule.GlobalRegistry.Register("sr_level", NewSRLevelRule, "支撑压力位")
+}
+
+// 支撑压力规则触发方式
+const (
+	srApproach = "approach" // 接近区间
+	srBreak    = "break"    // 突破/跌破区间
+	srBoth     = "both"
+)
+
+// SRLevelRule 支撑压力位规则：价格接近或突破附近最强的支撑/压力区间时触发
+type SRLevelRule struct {
+	name      string
+	left      int
+	right     int
+	tolerance float64 // 摆动点聚类容差%
+	proximity float64 // 接近阈值%
+	scope     float64 // 只考虑距当前价该百分比范围内的区间
+	mode      string
+	stockCode string
+	klineType model.KLineType
+	level     model.AlertLevel
+}
+
+// NewSRLevelRule 创建规则
+func NewSRLevelRule(name string, level model.AlertLevel, params map[string]interface{}) (rule.Rule, error) {
+	stockCode, _ := params["stock_code"].(string)
+
+	return &SRLevelRule{
+		name:      name,
+		left:      intParam(params, "left", 5),
+		right:     intParam(params, "right", 5),
+		tolerance: floatParam(params, "tolerance", 1),
+		proximity: floatParam(params, "proximity", 1),
+		scope:     floatParam(params, "scope", 10),
+		mode:      stringParam(params, "mode", srBoth),
+		stockCode: stockCode,
+		klineType: klineTypeParam(params),
+		level:     level,
+	}, nil
+}
+
+func (r *SRLevelRule) Name() string               { return r.name }
+func (r *SRLevelRule) StockCode() string          { return r.stockCode }
+func (r *SRLevelRule) KLineType() model.KLineType { return r.klineType }
+
+func (r *SRLevelRule) Description() string {
+	return fmt.Sprintf("%s K线支撑压力位 (强度%d/%d, 接近%.2f%%)", r.klineType, r.left, r.right, r.proximity)
+}
+
+func (r *SRLevelRule) Validate() error {
+	if r.left <= 0 || r.right <= 0 {
+		return fmt.Errorf("left and right must be positive")
+	}
+	if r.tolerance < 0 || r.proximity <= 0 || r.scope <= 0 {
+		return fmt.Errorf("tolerance, proximity and scope must be positive")
+	}
+	switch r.mode {
+	case srApproach, srBreak, srBoth:
+	default:
+		return fmt.Errorf("unknown mode: %s", r.mode)
+	}
+	return nil
+}
+
+func (r *SRLevelRule) Evaluate(ctx context.Context, ruleCtx *rule.RuleContext) (*rule.RuleResult, error) {
+	stock := ruleCtx.Stock
+	if r.stockCode != "" && stock.Code != r.stockCode {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	if ruleCtx.KLines == nil || stock.Price <= 0 {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	last := lastCompletedIndex(ruleCtx.KLines, stock.Time)
+	if last < r.left+r.right {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	lines := ruleCtx.KLines.Lines[:last+1]
+	prevClose := lines[last].Close
+
+	// 附近最强区间（SRZones 已按强度降序）
+	var zone *indicator.Zone
+	zones := indicator.SRZones(lines, r.left, r.right, r.tolerance)
+	for i := range zones {
+		if math.Abs(zones[i].Center-stock.Price)/stock.Price*100 <= r.scope {
+			zone = &zones[i]
+			break
+		}
+	}
+	if zone == nil {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	kind := "支撑"
+	if zone.Center > prevClose {
+		kind = "压力"
+	}
+
+	var message, event string
+	switch {
+	case r.mode != srApproach && prevClose <= zone.High && stock.Price > zone.High:
+		event = "break_up"
+		message = fmt.Sprintf("%s 价格 %.2f 突破%s区间 %.2f-%.2f", stock.Name, stock.Price, kind, zone.Low, zone.High)
+	case r.mode != srApproach && prevClose >= zone.Low && stock.Price < zone.Low:
+		event = "break_down"
+		message = fmt.Sprintf("%s 价格 %.2f 跌破%s区间 %.2f-%.2f", stock.Name, stock.Price, kind, zone.Low, zone.High)
+	case r.mode != srBreak && zoneDistance(stock.Price, *zone) <= r.proximity:
+		event = "approach"
+		message = fmt.Sprintf("%s 价格 %.2f 接近%s区间 %.2f-%.2f", stock.Name, stock.Price, kind, zone.Low, zone.High)
+	}
+
+	if message == "" {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	return &rule.RuleResult{
+		Triggered: true,
+		RuleName:  r.name,
+		Level:     r.level,
+		Message:   message,
+		Extra: map[string]interface{}{
+			"event":     event,
+			"zone_kind": kind,
+			"zone_low":  zone.Low,
+			"zone_high": zone.High,
+			"touches":   zone.Touches,
+			"score":     zone.Score,
+		},
+	}, nil
+}
+
+// zoneDistance 价格到区间的距离百分比，落在区间内为0
+func zoneDistance(price float64, zone indicator.Zone) float64 {
+	if zone.Contains(price) {
+		return 0
+	}
+	edge := zone.Low
+	if price > zone.High {
+		edge = zone.High
+	}
+	return math.Abs(price-edge) / price * 100
+}
@@ -0,0 +1,64 @@
+package rules
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+)
+
+func TestSRLevelRule(t *testing.T) {
+	// 压力区间 11.9-12，支撑区间 8-8.05，最后一根收盘 9.5；15 附近的高点超出 scope
+	hl := [][2]float64{{10, 9}, {12, 9.5}, {10, 8}, {11.9, 9.5}, {10, 8.05}, {15, 9.5}, {10, 9}}
+	klines := &model.KLineData{Code: "600519", Type: model.KLineDaily}
+	for _, b := range hl {
+		klines.Lines = append(klines.Lines, model.KLine{High: b[0], Low: b[1], Close: (b[0] + b[1]) / 2, Volume: 100})
+	}
+	params := func(mode string) map[string]interface{} {
+		return map[string]interface{}{"left": 1, "right": 1, "mode": mode}
+	}
+
+	tests := []struct {
+		name    string
+		mode    string
+		price   float64
+		event   string // 空表示不触发
+		message string
+	}{
+		{"进入压力区间", "both", 11.9, "approach", "接近压力区间 11.90-12.00"},
+		{"突破压力区间", "both", 12.1, "break_up", "突破压力区间 11.90-12.00"},
+		{"跌破支撑区间", "both", 7.9, "break_down", "跌破支撑区间 8.00-8.05"},
+		{"只看突破时接近不触发", "break", 11.85, "", ""},
+		{"只看接近时突破不触发", "approach", 12.5, "", ""},
+		{"远离区间", "both", 11, "", ""},
+	}
+	for _, tt := range tests {
+		r := mustRule(t, NewSRLevelRule, params(tt.mode))
+		stock := &model.Stock{Code: "600519", Name: "贵州茅台", Price: tt.price}
+		result, err := r.Evaluate(context.Background(), &rule.RuleContext{Stock: stock, KLines: klines})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Triggered != (tt.event != "") {
+			t.Errorf("%s: triggered = %v (%s), want event %q", tt.name, result.Triggered, result.Message, tt.event)
+			continue
+		}
+		if !result.Triggered {
+			continue
+		}
+		if result.Extra["event"] != tt.event || !strings.Contains(result.Message, tt.message) {
+			t.Errorf("%s: event %v message %q, want %s %q", tt.name, result.Extra["event"], result.Message, tt.event, tt.message)
+		}
+	}
+}
+
+func TestSRLevelRuleTooFewBars(t *testing.T) {
+	r := mustRule(t, NewSRLevelRule, map[string]interface{}{})
+	klines := &model.KLineData{Code: "600519", Type: model.KLineDaily, Lines: make([]model.KLine, 10)}
+	result, err := r.Evaluate(context.Background(), &rule.RuleContext{Stock: &model.Stock{Code: "600519", Price: 10}, KLines: klines})
+	if err != nil || result.Triggered {
+		t.Errorf("Evaluate with 10 bars and left=right=5 = %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,82 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+)
+
+func init() {
+	rule.GlobalRegistry.Register("turnover_rate", NewTurnoverRateRule, "换手率")
+}
+
+// TurnoverRateRule 换手率规则：换手率高于（或低于）阈值时触发
+type TurnoverRateRule struct {
+	name      string
+	threshold float64 // 换手率阈值 %
+	op        string  // above / below
+	stockCode string
+	level     model.AlertLevel
+}
+
+// NewTurnoverRateRule 创建规则
+func NewTurnoverRateRule(name string, level model.AlertLevel, params map[string]interface{}) (rule.Rule, error) {
+	stockCode, _ := params["stock_code"].(string)
+
+	return &TurnoverRateRule{
+		name:      name,
+		threshold: floatParam(params, "threshold", 10),
+		op:        stringParam(params, "op", "above"),
+		stockCode: stockCode,
+		level:     level,
+	}, nil
+}
+
+func (r *TurnoverRateRule) Name() string { return r.name }
+
+func (r *TurnoverRateRule) Description() string {
+	if r.op == "below" {
+		return fmt.Sprintf("换手率低于 %.2f%%", r.threshold)
+	}
+	return fmt.Sprintf("换手率超过 %.2f%%", r.threshold)
+}
+
+func (r *TurnoverRateRule) Validate() error {
+	if r.threshold <= 0 {
+		return fmt.Errorf("threshold must be positive")
+	}
+	switch r.op {
+	case "above", "below":
+	default:
+		return fmt.Errorf("unknown op: %s", r.op)
+	}
+	return nil
+}
+
+func (r *TurnoverRateRule) Evaluate(ctx context.Context, ruleCtx *rule.RuleContext) (*rule.RuleResult, error) {
+	stock := ruleCtx.Stock
+	if r.stockCode != "" && stock.Code != r.stockCode {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	f := fundamentalsOf(ruleCtx)
+	if f == nil || f.TurnoverRate <= 0 {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	if (r.op == "above" && f.TurnoverRate <= r.threshold) || (r.op == "below" && f.TurnoverRate >= r.threshold) {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	return &rule.RuleResult{
+		Triggered: true,
+		RuleName:  r.name,
+		Level:     r.level,
+		Message: fmt.Sprintf("%s 换手率 %.2f%% (流通市值 %.0f 亿，涨跌幅 %+.2f%%)",
+			stock.Name, f.TurnoverRate, f.FloatMarketCap/1e8, stock.ChangePercent()),
+		Extra: map[string]interface{}{
+			"turnover_rate":    f.TurnoverRate,
+			"float_market_cap": f.FloatMarketCap,
+		},
+	}, nil
+}
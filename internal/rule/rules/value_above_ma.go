@@ -0,0 +1,107 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+
+	"stock-monitor/internal/indicator"
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+)
+
+func init() {
+	rule.GlobalRegistry.Register("value_above_ma", NewValueAboveMARule, "低估值站上均线")
+}
+
+// ValueAboveMARule 低估值站上均线规则：市盈率(TTM)为正且低于阈值，现价高于均线时触发
+type ValueAboveMARule struct {
+	name      string
+	peMax     float64
+	pbMax     float64 // 为0时不限制市净率
+	period    int
+	stockCode string
+	klineType model.KLineType
+	level     model.AlertLevel
+}
+
+// NewValueAboveMARule 创建规则
+//
+// 均线按已完成K线的收盘价计算；亏损（市盈率为负）的股票不触发。
+func NewValueAboveMARule(name string, level model.AlertLevel, params map[string]interface{}) (rule.Rule, error) {
+	stockCode, _ := params["stock_code"].(string)
+
+	return &ValueAboveMARule{
+		name:      name,
+		peMax:     floatParam(params, "pe_max", 15),
+		pbMax:     floatParam(params, "pb_max", 0),
+		period:    intParam(params, "period", 60),
+		stockCode: stockCode,
+		klineType: klineTypeParam(params),
+		level:     level,
+	}, nil
+}
+
+func (r *ValueAboveMARule) Name() string               { return r.name }
+func (r *ValueAboveMARule) StockCode() string          { return r.stockCode }
+func (r *ValueAboveMARule) KLineType() model.KLineType { return r.klineType }
+
+func (r *ValueAboveMARule) Description() string {
+	if r.pbMax > 0 {
+		return fmt.Sprintf("PE低于 %.1f、PB低于 %.2f 且站上 %s MA%d", r.peMax, r.pbMax, r.klineType, r.period)
+	}
+	return fmt.Sprintf("PE低于 %.1f 且站上 %s MA%d", r.peMax, r.klineType, r.period)
+}
+
+func (r *ValueAboveMARule) Validate() error {
+	if r.peMax <= 0 {
+		return fmt.Errorf("pe_max must be positive")
+	}
+	if r.pbMax < 0 {
+		return fmt.Errorf("pb_max must not be negative")
+	}
+	if r.period <= 0 {
+		return fmt.Errorf("period must be positive")
+	}
+	return nil
+}
+
+func (r *ValueAboveMARule) Evaluate(ctx context.Context, ruleCtx *rule.RuleContext) (*rule.RuleResult, error) {
+	stock := ruleCtx.Stock
+	if r.stockCode != "" && stock.Code != r.stockCode {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	f := fundamentalsOf(ruleCtx)
+	if f == nil || f.PE <= 0 || f.PE >= r.peMax || (r.pbMax > 0 && (f.PB <= 0 || f.PB >= r.pbMax)) {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	if ruleCtx.KLines == nil {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	last := lastCompletedIndex(ruleCtx.KLines, stock.Time)
+	if last+1 < r.period {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	closes := make([]float64, last+1)
+	for i := range closes {
+		closes[i] = ruleCtx.KLines.Lines[i].Close
+	}
+	maValue := indicator.LastMA(closes, r.period)
+	if stock.Price <= maValue {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	return &rule.RuleResult{
+		Triggered: true,
+		RuleName:  r.name,
+		Level:     r.level,
+		Message: fmt.Sprintf("%s PE %.1f PB %.2f，现价 %.2f 站上 MA%d (%.2f)",
+			stock.Name, f.PE, f.PB, stock.Price, r.period, maValue),
+		Extra: map[string]interface{}{
+			"pe":       f.PE,
+			"pb":       f.PB,
+			"ma_value": maValue,
+			"period":   r.period,
+		},
+	}, nil
+}
@@ -0,0 +1,150 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"stock-monitor/internal/indicator"
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+	"stock-monitor/internal/security"
+)
+
+func init() {
+	rule.GlobalRegistry.Register("vwap", NewVWAPRule, "分时均价线")
+}
+
+// VWAP 规则触发方式
+const (
+	vwapCrossAbove = "cross_above" // 上穿均价线
+	vwapCrossBelow = "cross_below" // 下穿均价线
+	vwapCross      = "cross"       // 上穿或下穿
+	vwapDeviation  = "deviation"   // 偏离均价线超过阈值
+)
+
+// VWAPRule 分时均价线规则
+type VWAPRule struct {
+	name      string
+	mode      string
+	deviation float64
+	stockCode string
+	level     model.AlertLevel
+
+	mu    sync.Mutex
+	sides map[string]vwapSide
+}
+
+// vwapSide 上一次价格相对均价线的位置，换日后不再与前一交易日比较
+type vwapSide struct {
+	day  time.Time
+	side int // 1上方/-1下方
+}
+
+// NewVWAPRule 创建规则
+//
+// 参数 mode 为 cross_above / cross_below / cross / deviation，
+// deviation 为偏离百分比阈值（mode=deviation 时使用）。
+func NewVWAPRule(name string, level model.AlertLevel, params map[string]interface{}) (rule.Rule, error) {
+	stockCode, _ := params["stock_code"].(string)
+
+	return &VWAPRule{
+		name:      name,
+		mode:      stringParam(params, "mode", vwapCross),
+		deviation: floatParam(params, "deviation", 2),
+		stockCode: stockCode,
+		level:     level,
+		sides:     make(map[string]vwapSide),
+	}, nil
+}
+
+func (r *VWAPRule) Name() string { return r.name }
+
+func (r *VWAPRule) Description() string {
+	switch r.mode {
+	case vwapCrossAbove:
+		return "价格上穿分时均价线"
+	case vwapCrossBelow:
+		return "价格下穿分时均价线"
+	case vwapDeviation:
+		return fmt.Sprintf("价格偏离分时均价线超过 %.2f%%", r.deviation)
+	}
+	return "价格穿越分时均价线"
+}
+
+func (r *VWAPRule) Validate() error {
+	switch r.mode {
+	case vwapCrossAbove, vwapCrossBelow, vwapCross, vwapDeviation:
+	default:
+		return fmt.Errorf("unknown mode: %s", r.mode)
+	}
+	if r.mode == vwapDeviation && r.deviation <= 0 {
+		return fmt.Errorf("deviation must be positive")
+	}
+	return nil
+}
+
+func (r *VWAPRule) Evaluate(ctx context.Context, ruleCtx *rule.RuleContext) (*rule.RuleResult, error) {
+	stock := ruleCtx.Stock
+	if r.stockCode != "" && stock.Code != r.stockCode {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+
+	vwap := indicator.QuoteVWAP(stock)
+	if vwap <= 0 || stock.Price <= 0 {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	deviation := (stock.Price - vwap) / vwap * 100
+
+	side := 0
+	switch {
+	case stock.Price > vwap:
+		side = 1
+	case stock.Price < vwap:
+		side = -1
+	}
+
+	day := security.MarketOf(stock.Code).Date(stock.Time)
+	r.mu.Lock()
+	last, seen := r.sides[stock.Code]
+	seen = seen && last.day.Equal(day)
+	prev := last.side
+	if side != 0 {
+		r.sides[stock.Code] = vwapSide{day: day, side: side}
+	}
+	r.mu.Unlock()
+
+	var message string
+	switch r.mode {
+	case vwapDeviation:
+		if math.Abs(deviation) >= r.deviation {
+			message = fmt.Sprintf("%s 价格 %.2f 偏离均价线 %.2f 达 %.2f%%",
+				stock.Name, stock.Price, vwap, deviation)
+		}
+	default:
+		if !seen || side == 0 || side == prev {
+			break
+		}
+		if side > 0 && r.mode != vwapCrossBelow {
+			message = fmt.Sprintf("%s 价格 %.2f 上穿均价线 %.2f", stock.Name, stock.Price, vwap)
+		} else if side < 0 && r.mode != vwapCrossAbove {
+			message = fmt.Sprintf("%s 价格 %.2f 下穿均价线 %.2f", stock.Name, stock.Price, vwap)
+		}
+	}
+
+	if message == "" {
+		return &rule.RuleResult{Triggered: false}, nil
+	}
+	return &rule.RuleResult{
+		Triggered: true,
+		RuleName:  r.name,
+		Level:     r.level,
+		Message:   message,
+		Extra: map[string]interface{}{
+			"vwap":      vwap,
+			"deviation": deviation,
+		},
+	}, nil
+}
@@ -0,0 +1,78 @@
+package rules
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"stock-monitor/internal/model"
+	"stock-monitor/internal/rule"
+	"stock-monitor/internal/security"
+)
+
+func TestVWAPRuleCross(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 1, day, hour, minute, 0, 0, security.MarketCN.Location)
+	}
+	r := mustRule(t, NewVWAPRule, map[string]interface{}{})
+
+	// 均价线固定为 10.00（成交额 / 成交量）
+	steps := []struct {
+		time  time.Time
+		price float64
+		want  string // 期望的告警内容，空表示不触发
+	}{
+		{at(2, 9, 31), 10.1, ""}, // 首次评估只记录位置
+		{at(2, 9, 32), 9.9, "下穿均价线 10.00"},
+		{at(2, 9, 33), 9.8, ""},
+		{at(2, 9, 34), 10.0, ""}, // 恰好在均价线上不改变位置
+		{at(2, 9, 35), 10.2, "上穿均价线 10.00"},
+		{at(3, 9, 31), 9.9, ""}, // 换日后不与前一日收盘时的位置比较
+		{at(3, 9, 32), 10.1, "上穿均价线 10.00"},
+	}
+	for i, s := range steps {
+		stock := &model.Stock{Code: "600519", Name: "贵州茅台", Price: s.price, Amount: 1e7, Volume: 1e6, Time: s.time}
+		result, err := r.Evaluate(context.Background(), &rule.RuleContext{Stock: stock})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Triggered != (s.want != "") {
+			t.Fatalf("step %d (%s %.2f): triggered = %v, want %q", i, s.time.Format("01-02 15:04"), s.price, result.Triggered, s.want)
+		}
+		if result.Triggered && !strings.Contains(result.Message, s.want) {
+			t.Errorf("step %d: message = %q, want %q", i, result.Message, s.want)
+		}
+	}
+}
+
+func TestVWAPRuleModes(t *testing.T) {
+	quote := func(price float64) *rule.RuleContext {
+		return &rule.RuleContext{Stock: &model.Stock{Code: "000001", Name: "平安银行", Price: price, Amount: 1e7, Volume: 1e6}}
+	}
+
+	above := mustRule(t, NewVWAPRule, map[string]interface{}{"mode": "cross_above"})
+	var fired []bool
+	for _, price := range []float64{9.9, 10.1, 9.9} {
+		result, _ := above.Evaluate(context.Background(), quote(price))
+		fired = append(fired, result.Triggered)
+	}
+	if fired[0] || !fired[1] || fired[2] {
+		t.Errorf("cross_above fired = %v, want [false true false]", fired)
+	}
+
+	deviation := mustRule(t, NewVWAPRule, map[string]interface{}{"mode": "deviation", "deviation": 2})
+	result, _ := deviation.Evaluate(context.Background(), quote(10.3))
+	if !result.Triggered || !strings.Contains(result.Message, "偏离均价线 10.00 达 3.00%") {
+		t.Errorf("deviation result = %+v", result)
+	}
+	if got := result.Extra["deviation"].(float64); got < 2.999 || got > 3.001 {
+		t.Errorf("extra deviation = %v, want 3", got)
+	}
+	if result, _ := deviation.Evaluate(context.Background(), quote(10.1)); result.Triggered {
+		t.Error("1% deviation should not trigger")
+	}
+	if result, _ := deviation.Evaluate(context.Background(), &rule.RuleContext{Stock: &model.Stock{Code: "000001", Price: 10.3}}); result.Triggered {
+		t.Error("quote without volume should not trigger")
+	}
+}
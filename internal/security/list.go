@@ -0,0 +1,173 @@
+package security
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Entry 证券列表条目
+type Entry struct {
+	Security
+	Name   string `json:"name"`   // 证券简称
+	Pinyin string `json:"pinyin"` // 拼音首字母
+}
+
+// List 证券列表，支持按代码、名称、拼音首字母检索
+type List struct {
+	mu      sync.RWMutex
+	entries []Entry
+	byCode  map[string]Entry // key 为规范代码
+}
+
+// NewList 创建空证券列表
+func NewList() *List {
+	return &List{byCode: make(map[string]Entry)}
+}
+
+// Len 证券数量
+func (l *List) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.entries)
+}
+
+// Replace 用新的快照替换整个列表，未填写拼音的条目自动计算
+func (l *List) Replace(entries []Entry) {
+	byCode := make(map[string]Entry, len(entries))
+	list := make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		if e.Pinyin == "" {
+			e.Pinyin = PinyinInitials(e.Name)
+		}
+		key := e.Canonical()
+		if _, dup := byCode[key]; dup {
+			continue
+		}
+		byCode[key] = e
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Symbol() < list[j].Symbol() })
+
+	l.mu.Lock()
+	l.entries = list
+	l.byCode = byCode
+	l.mu.Unlock()
+}
+
+// Lookup 按代码查找，代码写法同 Parse
+func (l *List) Lookup(code string) (Entry, bool) {
+	sec, err := Parse(code)
+	if err != nil {
+		return Entry{}, false
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	e, ok := l.byCode[sec.Canonical()]
+	return e, ok
+}
+
+// Search 检索证券：代码前缀、名称包含、拼音首字母前缀，前缀匹配排在前面
+//
+// 拼音首字母由 PinyinInitials 推算，名称含未收录的生僻字时缺少对应字母，
+// 可在离线CSV的第三列填写拼音首字母覆盖。
+func (l *List) Search(query string, limit int) []Entry {
+	raw := strings.TrimSpace(query)
+	q := strings.ToLower(raw)
+	if q == "" {
+		return nil
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var prefix, contains []Entry
+	for _, e := range l.entries {
+		switch {
+		case strings.HasPrefix(e.Code, q), strings.HasPrefix(e.Symbol(), q),
+			strings.HasPrefix(e.Name, raw), strings.HasPrefix(e.Pinyin, q):
+			prefix = append(prefix, e)
+		case strings.Contains(e.Name, raw), strings.Contains(e.Pinyin, q):
+			contains = append(contains, e)
+		}
+		if limit > 0 && len(prefix) >= limit {
+			break
+		}
+	}
+
+	result := append(prefix, contains...)
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// LoadCSV 从CSV加载证券列表
+//
+// 每行 "代码,名称[,拼音首字母]"，代码可带交易所前缀（指数需带前缀），首行表头可选。
+func (l *List) LoadCSV(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var entries []Entry
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if len(record) < 2 {
+			continue
+		}
+		sec, err := Parse(record[0])
+		if err != nil {
+			if line == 1 {
+				continue // 表头
+			}
+			return fmt.Errorf("第%d行: %w", line, err)
+		}
+		e := Entry{Security: sec, Name: strings.TrimSpace(record[1])}
+		if len(record) > 2 {
+			e.Pinyin = strings.ToLower(strings.TrimSpace(record[2]))
+		}
+		entries = append(entries, e)
+	}
+
+	l.Replace(entries)
+	return nil
+}
+
+// SaveCSV 将证券列表保存为CSV，便于离线加载
+func (l *List) SaveCSV(path string) error {
+	l.mu.RLock()
+	entries := make([]Entry, len(l.entries))
+	copy(entries, l.entries)
+	l.mu.RUnlock()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"code", "name", "pinyin"})
+	for _, e := range entries {
+		w.Write([]string{e.Symbol(), e.Name, e.Pinyin})
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,112 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testList(t *testing.T) *List {
+	t.Helper()
+	var entries []Entry
+	for _, item := range []struct{ code, name string }{
+		{"600519", "贵州茅台"},
+		{"000001", "平安银行"},
+		{"sh000001", "上证指数"},
+		{"601318", "中国平安"},
+		{"000858", "五粮液"},
+		{"hk00700", "腾讯控股"},
+	} {
+		sec, err := Parse(item.code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, Entry{Security: sec, Name: item.name})
+	}
+	l := NewList()
+	l.Replace(entries)
+	return l
+}
+
+func TestListLookup(t *testing.T) {
+	l := testList(t)
+	tests := []struct {
+		code, want string
+	}{
+		{"600519", "贵州茅台"},
+		{"600519.SH", "贵州茅台"},
+		{"sh600519", "贵州茅台"},
+		{"000001", "平安银行"},
+		{"sh000001", "上证指数"},
+		{"0700.HK", "腾讯控股"},
+		{"600000", ""},
+	}
+	for _, tt := range tests {
+		e, ok := l.Lookup(tt.code)
+		if ok != (tt.want != "") || e.Name != tt.want {
+			t.Errorf("Lookup(%s) = %q/%v, want %q", tt.code, e.Name, ok, tt.want)
+		}
+	}
+}
+
+func TestListSearch(t *testing.T) {
+	l := testList(t)
+	tests := []struct {
+		query string
+		limit int
+		want  []string
+	}{
+		{"gzmt", 0, []string{"贵州茅台"}},
+		{"GZ", 0, []string{"贵州茅台"}},
+		{"6005", 0, []string{"贵州茅台"}},
+		{"茅台", 0, []string{"贵州茅台"}},
+		{"平安", 0, []string{"平安银行", "中国平安"}}, // 名称前缀排在包含之前
+		{"pa", 0, []string{"平安银行", "中国平安"}},
+		{"hk007", 0, []string{"腾讯控股"}},
+		{"平安", 1, []string{"平安银行"}},
+		{" ", 0, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, e := range l.Search(tt.query, tt.limit) {
+			got = append(got, e.Name)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestListCSVRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "securities.csv")
+	if err := testList(t).SaveCSV(path); err != nil {
+		t.Fatal(err)
+	}
+	l := NewList()
+	if err := l.LoadCSV(path); err != nil {
+		t.Fatal(err)
+	}
+	if l.Len() != 6 {
+		t.Fatalf("Len = %d, want 6", l.Len())
+	}
+	if e, ok := l.Lookup("sh000001"); !ok || e.Name != "上证指数" || e.Type != TypeIndex {
+		t.Errorf("sh000001 = %+v/%v, want 上证指数 index", e, ok)
+	}
+	if e, _ := l.Lookup("600519"); e.Pinyin != "gzmt" {
+		t.Errorf("600519 pinyin = %q, want gzmt", e.Pinyin)
+	}
+
+	if err := os.WriteFile(path, []byte("code,name\n600519,贵州茅台\nbad,坏数据\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewList().LoadCSV(path); err == nil {
+		t.Error("LoadCSV with invalid code should fail")
+	}
+}
@@ -0,0 +1,173 @@
+package security
+
+import (
+	"time"
+	_ "time/tzdata" // 容器镜像可能没有时区数据
+)
+
+// Session 连续交易时段，以当地零点起的分钟数表示
+type Session struct {
+	Open  int
+	Close int
+}
+
+// Market 市场交易时段与时区
+type Market struct {
+	Name     string
+	Location *time.Location
+	Sessions []Session
+}
+
+var (
+	// MarketCN A股，沿用本地时区（部署时应设为 Asia/Shanghai）
+	MarketCN = &Market{
+		Name:     "cn",
+		Location: time.Local,
+		Sessions: []Session{{9*60 + 30, 11*60 + 30}, {13 * 60, 15 * 60}},
+	}
+	// MarketHK 港股
+	MarketHK = &Market{
+		Name:     "hk",
+		Location: mustLoadLocation("Asia/Hong_Kong"),
+		Sessions: []Session{{9*60 + 30, 12 * 60}, {13 * 60, 16 * 60}},
+	}
+	// MarketUS 美股（常规交易时段）
+	MarketUS = &Market{
+		Name:     "us",
+		Location: mustLoadLocation("America/New_York"),
+		Sessions: []Session{{9*60 + 30, 16 * 60}},
+	}
+)
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+// Market 证券所属市场
+func (s Security) Market() *Market {
+	return s.Exchange.Market()
+}
+
+// Market 交易所所属市场
+func (e Exchange) Market() *Market {
+	switch e {
+	case ExchangeHK:
+		return MarketHK
+	case ExchangeUS:
+		return MarketUS
+	}
+	return MarketCN
+}
+
+// MarketOf 按代码返回所属市场，无法解析时视为A股
+func MarketOf(code string) *Market {
+	sec, err := Parse(code)
+	if err != nil {
+		return MarketCN
+	}
+	return sec.Market()
+}
+
+// Minutes 每个交易日的交易分钟数
+func (m *Market) Minutes() int {
+	total := 0
+	for _, s := range m.Sessions {
+		total += s.Close - s.Open
+	}
+	return total
+}
+
+// In 转换为市场当地时间
+func (m *Market) In(t time.Time) time.Time {
+	return t.In(m.Location)
+}
+
+// Date 时刻所在的当地交易日零点
+func (m *Market) Date(t time.Time) time.Time {
+	t = t.In(m.Location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, m.Location)
+}
+
+// CloseTime 交易日 day（按其年月日）的收盘时间
+func (m *Market) CloseTime(day time.Time) time.Time {
+	y, mo, d := day.Date()
+	last := m.Sessions[len(m.Sessions)-1]
+	return time.Date(y, mo, d, 0, 0, 0, 0, m.Location).Add(time.Duration(last.Close) * time.Minute)
+}
+
+// OpenTime 交易日 day（按其年月日）的开盘时间
+func (m *Market) OpenTime(day time.Time) time.Time {
+	y, mo, d := day.Date()
+	return time.Date(y, mo, d, 0, 0, 0, 0, m.Location).Add(time.Duration(m.Sessions[0].Open) * time.Minute)
+}
+
+// IsTradingDay 是否为工作日（不含节假日）
+func (m *Market) IsTradingDay(t time.Time) bool {
+	wd := t.In(m.Location).Weekday()
+	return wd != time.Saturday && wd != time.Sunday
+}
+
+// TradingMinute 将时刻换算为所属1分钟K线的结束分钟数(1~Minutes)
+//
+// K线时间为结束时间，分钟内已有成交即属于下一根；开盘前计入第一根，
+// 午休计入上午最后一根，收盘后计入最后一根。
+func (m *Market) TradingMinute(t time.Time) int {
+	t = t.In(m.Location)
+	clock := t.Hour()*60 + t.Minute()
+	if t.Second() > 0 || t.Nanosecond() > 0 {
+		clock++
+	}
+	elapsed := 0
+	for _, s := range m.Sessions {
+		if clock <= s.Open {
+			break
+		}
+		if clock <= s.Close {
+			elapsed += clock - s.Open
+			break
+		}
+		elapsed += s.Close - s.Open
+	}
+	return max(elapsed, 1)
+}
+
+// BarEnd 由已交易分钟数还原交易日 day 的K线结束时间
+func (m *Market) BarEnd(day time.Time, minute int) time.Time {
+	base := m.Date(day)
+	for _, s := range m.Sessions {
+		if minute <= s.Close-s.Open {
+			return base.Add(time.Duration(s.Open+minute) * time.Minute)
+		}
+		minute -= s.Close - s.Open
+	}
+	last := m.Sessions[len(m.Sessions)-1]
+	return base.Add(time.Duration(last.Close) * time.Minute)
+}
+
+// MinutesBetween 计算 (from, to] 之间工作日交易时段的分钟数
+func (m *Market) MinutesBetween(from, to time.Time) int {
+	total := 0
+	for day := m.Date(from); !day.After(to); day = day.AddDate(0, 0, 1) {
+		if !m.IsTradingDay(day) {
+			continue
+		}
+		for _, s := range m.Sessions {
+			start := day.Add(time.Duration(s.Open) * time.Minute)
+			end := day.Add(time.Duration(s.Close) * time.Minute)
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if end.After(start) {
+				total += int(end.Sub(start) / time.Minute)
+			}
+		}
+	}
+	return total
+}
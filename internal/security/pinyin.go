@@ -0,0 +1,85 @@
+package security
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// gb2312Initials GB2312 一级汉字按拼音排序，各声母首字的区位码
+var gb2312Initials = []struct {
+	start  uint16
+	letter byte
+}{
+	{0xB0A1, 'a'}, {0xB0C5, 'b'}, {0xB2C1, 'c'}, {0xB4EE, 'd'}, {0xB6EA, 'e'},
+	{0xB7A2, 'f'}, {0xB8C1, 'g'}, {0xB9FE, 'h'}, {0xBBF7, 'j'}, {0xBFA6, 'k'},
+	{0xC0AC, 'l'}, {0xC2E8, 'm'}, {0xC4C3, 'n'}, {0xC5B6, 'o'}, {0xC5BE, 'p'},
+	{0xC6DA, 'q'}, {0xC8BB, 'r'}, {0xC8F6, 's'}, {0xCBFA, 't'}, {0xCDDA, 'w'},
+	{0xCEF4, 'x'}, {0xD1B9, 'y'}, {0xD4D1, 'z'},
+}
+
+// gb2312Level1End GB2312 一级汉字结束区位码，之后的二级汉字按部首排序无法推算拼音
+const gb2312Level1End = 0xD7F9
+
+// polyphoneInitials 证券简称中常见多音字的读音
+var polyphoneInitials = map[rune]byte{
+	'行': 'h', // 银行
+	'长': 'c', // 长江、长城
+	'重': 'c', // 重庆
+	'厦': 'x', // 厦门
+	'藏': 'z', // 西藏
+	'乐': 'l', // 乐视、乐普
+}
+
+// rareInitials 证券简称中常见的 GB2312 二级汉字及 GBK 扩展汉字的读音，这些字无法按区位码推算
+var rareInitials = map[rune]byte{
+	'晟': 's', '珑': 'l', '璞': 'p', '鑫': 'x', '钰': 'y', '璟': 'j', '昊': 'h',
+	'琪': 'q', '煜': 'y', '炜': 'w', '昱': 'y', '琨': 'k', '璐': 'l', '瑛': 'y',
+	'祺': 'q', '禧': 'x', '淼': 'm', '骐': 'q', '旻': 'm', '赟': 'y', '垚': 'y',
+	'翊': 'y', '泓': 'h', '沣': 'f', '瀚': 'h', '睿': 'r', '宸': 'c', '昕': 'x',
+	'暄': 'x', '锂': 'l', '钴': 'g', '铖': 'c', '钛': 't', '钼': 'm', '锆': 'g',
+	'楠': 'n',
+}
+
+// PinyinInitials 计算名称的拼音首字母，如 贵州茅台 → gzmt
+//
+// 按区位码只能推算 GB2312 一级汉字，二级汉字和 GBK 扩展汉字仅识别 rareInitials 中
+// 收录的常用字，其余忽略；字母和数字保留并转为小写。
+func PinyinInitials(name string) string {
+	var b strings.Builder
+	enc := simplifiedchinese.GBK.NewEncoder()
+	for _, r := range name {
+		switch {
+		case r < unicode.MaxASCII:
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				b.WriteRune(unicode.ToLower(r))
+			}
+			continue
+		case polyphoneInitials[r] != 0:
+			b.WriteByte(polyphoneInitials[r])
+			continue
+		case rareInitials[r] != 0:
+			b.WriteByte(rareInitials[r])
+			continue
+		}
+
+		gbk, err := enc.Bytes([]byte(string(r)))
+		if err != nil || len(gbk) != 2 {
+			continue
+		}
+		code := uint16(gbk[0])<<8 | uint16(gbk[1])
+		if code < gb2312Initials[0].start || code > gb2312Level1End {
+			continue
+		}
+		letter := gb2312Initials[0].letter
+		for _, item := range gb2312Initials {
+			if code < item.start {
+				break
+			}
+			letter = item.letter
+		}
+		b.WriteByte(letter)
+	}
+	return b.String()
+}
@@ -0,0 +1,28 @@
+package security
+
+import "testing"
+
+func TestPinyinInitials(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"贵州茅台", "gzmt"},
+		{"平安银行", "payh"},
+		{"五粮液", "wly"},
+		{"长江电力", "cjdl"},
+		{"重庆啤酒", "cqpj"},
+		{"*ST国华", "stgh"},
+		{"沪深300ETF", "hs300etf"},
+		{"晟楠科技", "snkj"}, // GB2312 二级汉字
+		{"鑫科材料", "xkcl"},
+		{"珑璞", "lp"},
+		{"赟", "y"},  // GBK 扩展汉字
+		{"囧途", "t"}, // 未收录的二级汉字忽略
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := PinyinInitials(tt.name); got != tt.want {
+			t.Errorf("PinyinInitials(%s) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
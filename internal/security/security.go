@@ -0,0 +1,279 @@
+package security
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Exchange 交易所
+type Exchange string
+
+const (
+	ExchangeSH Exchange = "sh" // 上海证券交易所
+	ExchangeSZ Exchange = "sz" // 深圳证券交易所
+	ExchangeBJ Exchange = "bj" // 北京证券交易所
+	ExchangeHK Exchange = "hk" // 香港交易所
+	ExchangeUS Exchange = "us" // 美国市场（纳斯达克/纽交所等）
+)
+
+// Type 证券品种
+type Type string
+
+const (
+	TypeStock   Type = "stock"   // 股票
+	TypeIndex   Type = "index"   // 指数
+	TypeETF     Type = "etf"     // ETF
+	TypeFund    Type = "fund"    // LOF/封闭式基金
+	TypeBond    Type = "bond"    // 可转债
+	TypeUnknown Type = "unknown" // 代码格式正确但无法识别品种
+)
+
+// Security 证券代码分类结果
+type Security struct {
+	Code     string   `json:"code"`     // A股6位数字代码，港股5位数字代码，美股大写代码
+	Exchange Exchange `json:"exchange"` // 交易所
+	Type     Type     `json:"type"`     // 品种
+}
+
+// Symbol 带交易所前缀的代码，如 sh600519、hk00700、usAAPL
+func (s Security) Symbol() string {
+	return string(s.Exchange) + s.Code
+}
+
+// Overseas 是否为港股或美股
+func (s Security) Overseas() bool {
+	return s.Exchange == ExchangeHK || s.Exchange == ExchangeUS
+}
+
+// Canonical 规范代码：A股不带前缀也能唯一识别时返回6位代码，否则返回带前缀代码
+//
+// 例如 600519 → 600519，上证指数 sh000001 → sh000001（000001 默认为平安银行），
+// 港股美股始终带前缀，如 hk00700、usAAPL。
+func (s Security) Canonical() string {
+	if s.Overseas() {
+		return s.Symbol()
+	}
+	if bare, err := Parse(s.Code); err == nil && bare.Exchange == s.Exchange {
+		return s.Code
+	}
+	return s.Symbol()
+}
+
+// LimitRatio 涨跌停幅度：科创板、创业板及跟踪其指数的ETF 20%，北交所30%，其余10%
+//
+// 沪深主板ST股自2025年7月起同为10%。name 为证券简称，用于识别创业板、科创板ETF；
+// 指数、港股美股等无涨跌停限制的品种返回0。
+func (s Security) LimitRatio(name string) float64 {
+	if s.Overseas() {
+		return 0
+	}
+	switch s.Type {
+	case TypeIndex, TypeUnknown:
+		return 0
+	case TypeBond:
+		return 0.2
+	case TypeETF:
+		// 科创板ETF代码为 588 开头，其余按名称识别
+		if strings.HasPrefix(s.Code, "588") || strings.Contains(name, "科创") ||
+			strings.Contains(name, "创业板") || strings.Contains(name, "双创") {
+			return 0.2
+		}
+		return 0.1
+	}
+	switch {
+	case s.Exchange == ExchangeBJ:
+		return 0.3
+	case s.Exchange == ExchangeSH && strings.HasPrefix(s.Code, "68"),
+		s.Exchange == ExchangeSZ && strings.HasPrefix(s.Code, "30"):
+		return 0.2
+	}
+	return 0.1
+}
+
+// Tick 最小报价单位：ETF、LOF和可转债为0.001元，其余为0.01元
+func (s Security) Tick() float64 {
+	switch s.Type {
+	case TypeETF, TypeFund, TypeBond:
+		return 0.001
+	}
+	return 0.01
+}
+
+// LimitPrices 按昨收和涨跌停幅度计算涨停价、跌停价，四舍五入到最小报价单位 tick
+func LimitPrices(preClose, ratio, tick float64) (up, down float64) {
+	scale := math.Round(1 / tick)
+	up = math.Round(preClose*(1+ratio)*scale) / scale
+	down = math.Round(preClose*(1-ratio)*scale) / scale
+	return up, down
+}
+
+// Parse 解析证券代码
+//
+// 支持 600519、sh600519、SH600519、600519.SH 等写法。不带前缀时按代码段推断交易所，
+// 与指数重叠的代码（如 000001）按个股处理，指数需带前缀或使用 ParseIndex。
+// 港股写作 hk00700、00700、0700.HK，美股写作 usAAPL、AAPL.US。
+func Parse(code string) (Security, error) {
+	if sec, ok, err := parseOverseas(code); ok {
+		return sec, err
+	}
+	exchange, digits, err := split(code)
+	if err != nil {
+		return Security{}, err
+	}
+	if exchange == "" {
+		exchange = inferExchange(digits)
+		if exchange == "" {
+			return Security{}, fmt.Errorf("无法识别的证券代码: %s", code)
+		}
+	}
+	return Security{Code: digits, Exchange: exchange, Type: classify(exchange, digits)}, nil
+}
+
+// ParseIndex 按指数解析代码：不带前缀时 399 开头为深证指数，899 开头为北证指数，其余为上证指数
+//
+// 港股指数写作 hkHSI。
+func ParseIndex(code string) (Security, error) {
+	if sec, ok, err := parseOverseas(code); ok {
+		return sec, err
+	}
+	exchange, digits, err := split(code)
+	if err != nil {
+		return Security{}, err
+	}
+	if exchange == "" {
+		switch {
+		case strings.HasPrefix(digits, "399"):
+			exchange = ExchangeSZ
+		case strings.HasPrefix(digits, "899"):
+			exchange = ExchangeBJ
+		default:
+			exchange = ExchangeSH
+		}
+	}
+	return Security{Code: digits, Exchange: exchange, Type: classify(exchange, digits)}, nil
+}
+
+// parseOverseas 解析港股美股代码，不是港股美股写法时 ok 为 false
+func parseOverseas(code string) (sec Security, ok bool, err error) {
+	c := strings.TrimSpace(code)
+	lower := strings.ToLower(c)
+
+	var exchange Exchange
+	var rest string
+	switch {
+	case strings.HasPrefix(lower, "hk"), strings.HasPrefix(lower, "us"):
+		exchange, rest = Exchange(lower[:2]), c[2:]
+	case strings.HasSuffix(lower, ".hk"), strings.HasSuffix(lower, ".us"):
+		exchange, rest = Exchange(lower[len(lower)-2:]), c[:len(c)-3]
+	case len(c) == 5 && strings.Trim(c, "0123456789") == "":
+		exchange, rest = ExchangeHK, c
+	default:
+		return Security{}, false, nil
+	}
+
+	if exchange == ExchangeHK {
+		switch {
+		case rest != "" && len(rest) <= 5 && strings.Trim(rest, "0123456789") == "":
+			return Security{Code: fmt.Sprintf("%05s", rest), Exchange: ExchangeHK, Type: TypeStock}, true, nil
+		case isTicker(rest, false):
+			return Security{Code: strings.ToUpper(rest), Exchange: ExchangeHK, Type: TypeIndex}, true, nil
+		}
+		return Security{}, true, fmt.Errorf("无效的港股代码: %s", code)
+	}
+	if !isTicker(rest, true) {
+		return Security{}, true, fmt.Errorf("无效的美股代码: %s", code)
+	}
+	return Security{Code: strings.ToUpper(rest), Exchange: ExchangeUS, Type: TypeStock}, true, nil
+}
+
+// isTicker 字母开头的代码，美股允许数字、点和横线（如 BRK.B）
+func isTicker(s string, us bool) bool {
+	if s == "" || len(s) > 10 {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && us && (r >= '0' && r <= '9' || r == '.' || r == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// split 拆分交易所前缀/后缀与6位数字代码
+func split(code string) (Exchange, string, error) {
+	c := strings.ToLower(strings.TrimSpace(code))
+	var exchange Exchange
+	if i := strings.IndexByte(c, '.'); i >= 0 {
+		exchange, c = Exchange(c[i+1:]), c[:i]
+	} else if len(c) == 8 {
+		exchange, c = Exchange(c[:2]), c[2:]
+	}
+
+	switch exchange {
+	case "", ExchangeSH, ExchangeSZ, ExchangeBJ:
+	default:
+		return "", "", fmt.Errorf("无效的交易所: %s", code)
+	}
+	if len(c) != 6 || strings.Trim(c, "0123456789") != "" {
+		return "", "", fmt.Errorf("证券代码必须为6位数字: %s", code)
+	}
+	return exchange, c, nil
+}
+
+// inferExchange 按代码段推断交易所
+func inferExchange(code string) Exchange {
+	switch {
+	case strings.HasPrefix(code, "92"), code[0] == '4', code[0] == '8':
+		return ExchangeBJ
+	case code[0] == '5', code[0] == '6', code[0] == '9', strings.HasPrefix(code, "11"):
+		return ExchangeSH
+	case code[0] == '0', code[0] == '1', code[0] == '2', code[0] == '3':
+		return ExchangeSZ
+	}
+	return ""
+}
+
+// classify 按交易所和代码段识别品种
+func classify(exchange Exchange, code string) Type {
+	p2, p3 := code[:2], code[:3]
+	switch exchange {
+	case ExchangeSH:
+		switch {
+		case p2 == "60", p2 == "68", p3 == "900":
+			return TypeStock
+		case p3 == "000":
+			return TypeIndex
+		case p2 == "51", p2 == "56", p2 == "58":
+			return TypeETF
+		case p2 == "50":
+			return TypeFund
+		case p2 == "11":
+			return TypeBond
+		}
+	case ExchangeSZ:
+		switch {
+		case p3 == "399":
+			return TypeIndex
+		case p2 == "00", p2 == "30", p2 == "20":
+			return TypeStock
+		case p3 == "159":
+			return TypeETF
+		case p2 == "15", p2 == "16", p2 == "18":
+			return TypeFund
+		case p2 == "12":
+			return TypeBond
+		}
+	case ExchangeBJ:
+		switch {
+		case p3 == "899":
+			return TypeIndex
+		case code[0] == '4', code[0] == '8', p2 == "92":
+			return TypeStock
+		}
+	}
+	return TypeUnknown
+}
@@ -0,0 +1,130 @@
+package security
+
+import "testing"
+
+func TestLimitRatio(t *testing.T) {
+	tests := []struct {
+		code, name string
+		want       float64
+	}{
+		{"600519", "贵州茅台", 0.1},
+		{"600666", "*ST奥瑞", 0.1}, // 主板ST股自2025年7月起为10%
+		{"300750", "宁德时代", 0.2},
+		{"300313", "*ST天山", 0.2},
+		{"688981", "中芯国际", 0.2},
+		{"830799", "艾融软件", 0.3},
+		{"510300", "沪深300ETF", 0.1},
+		{"159915", "创业板ETF", 0.2},
+		{"588000", "科创50ETF", 0.2},
+		{"159781", "双创50ETF", 0.2},
+		{"113050", "南银转债", 0.2},
+		{"sh000001", "上证指数", 0},
+		{"hk00700", "腾讯控股", 0},
+	}
+	for _, tt := range tests {
+		sec, err := Parse(tt.code)
+		if err != nil {
+			t.Fatalf("Parse(%s): %v", tt.code, err)
+		}
+		if got := sec.LimitRatio(tt.name); got != tt.want {
+			t.Errorf("LimitRatio(%s %s) = %v, want %v", tt.code, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLimitPrices(t *testing.T) {
+	tests := []struct {
+		preClose, ratio, tick float64
+		up, down              float64
+	}{
+		{1685.32, 0.1, 0.01, 1853.85, 1516.79},
+		{10.05, 0.1, 0.01, 11.06, 9.05},
+		{3.857, 0.1, 0.001, 4.243, 3.471}, // ETF 按厘计算
+		{1.234, 0.2, 0.001, 1.481, 0.987},
+	}
+	for _, tt := range tests {
+		up, down := LimitPrices(tt.preClose, tt.ratio, tt.tick)
+		if up != tt.up || down != tt.down {
+			t.Errorf("LimitPrices(%v, %v, %v) = %v/%v, want %v/%v", tt.preClose, tt.ratio, tt.tick, up, down, tt.up, tt.down)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		code      string
+		exchange  Exchange
+		typ       Type
+		canonical string
+	}{
+		{"600519", ExchangeSH, TypeStock, "600519"},
+		{"sh600519", ExchangeSH, TypeStock, "600519"},
+		{"SH600519", ExchangeSH, TypeStock, "600519"},
+		{"600519.SH", ExchangeSH, TypeStock, "600519"},
+		{"000001", ExchangeSZ, TypeStock, "000001"},
+		{"sh000001", ExchangeSH, TypeIndex, "sh000001"},
+		{"300750", ExchangeSZ, TypeStock, "300750"},
+		{"510300", ExchangeSH, TypeETF, "510300"},
+		{"159915", ExchangeSZ, TypeETF, "159915"},
+		{"161725", ExchangeSZ, TypeFund, "161725"},
+		{"113050", ExchangeSH, TypeBond, "113050"},
+		{"123107", ExchangeSZ, TypeBond, "123107"},
+		{"830799", ExchangeBJ, TypeStock, "830799"},
+		{"920002", ExchangeBJ, TypeStock, "920002"},
+		{"hk00700", ExchangeHK, TypeStock, "hk00700"},
+		{"00700", ExchangeHK, TypeStock, "hk00700"},
+		{"0700.HK", ExchangeHK, TypeStock, "hk00700"},
+		{"hkHSI", ExchangeHK, TypeIndex, "hkHSI"},
+		{"usAAPL", ExchangeUS, TypeStock, "usAAPL"},
+		{"brk.b.us", ExchangeUS, TypeStock, "usBRK.B"},
+	}
+	for _, tt := range tests {
+		sec, err := Parse(tt.code)
+		if err != nil {
+			t.Errorf("Parse(%s): %v", tt.code, err)
+			continue
+		}
+		if sec.Exchange != tt.exchange || sec.Type != tt.typ {
+			t.Errorf("Parse(%s) = %s/%s, want %s/%s", tt.code, sec.Exchange, sec.Type, tt.exchange, tt.typ)
+		}
+		if got := sec.Canonical(); got != tt.canonical {
+			t.Errorf("Parse(%s).Canonical() = %s, want %s", tt.code, got, tt.canonical)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, code := range []string{"", "6005", "6005199", "abcdef", "600519.XX", "hk1234567", "us123", "700000"} {
+		if sec, err := Parse(code); err == nil {
+			t.Errorf("Parse(%q) = %+v, want error", code, sec)
+		}
+	}
+}
+
+func TestParseIndex(t *testing.T) {
+	tests := []struct {
+		code      string
+		exchange  Exchange
+		canonical string
+	}{
+		{"000001", ExchangeSH, "sh000001"},
+		{"000300", ExchangeSH, "sh000300"},
+		{"399006", ExchangeSZ, "399006"},
+		{"sz399001", ExchangeSZ, "399001"},
+		{"899050", ExchangeBJ, "899050"},
+		{"hkHSI", ExchangeHK, "hkHSI"},
+	}
+	for _, tt := range tests {
+		sec, err := ParseIndex(tt.code)
+		if err != nil {
+			t.Errorf("ParseIndex(%s): %v", tt.code, err)
+			continue
+		}
+		if sec.Exchange != tt.exchange || sec.Type != TypeIndex {
+			t.Errorf("ParseIndex(%s) = %s/%s, want %s/index", tt.code, sec.Exchange, sec.Type, tt.exchange)
+		}
+		if got := sec.Canonical(); got != tt.canonical {
+			t.Errorf("ParseIndex(%s).Canonical() = %s, want %s", tt.code, got, tt.canonical)
+		}
+	}
+}
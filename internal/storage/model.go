@@ -30,6 +30,7 @@ type NotifierConfig struct {
 	ServerChan ServerChanConfig `json:"serverchan"`
 	Feishu     FeishuConfig     `json:"feishu"`
 	DingTalk   DingTalkConfig   `json:"dingtalk"`
+	Market     FeishuConfig     `json:"market"` // 市场整体告警专用的飞书群，未启用时发送到上述渠道
 }
 
 type ServerChanConfig struct {